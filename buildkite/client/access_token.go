@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+)
+
+const (
+	ScopeReadPipelines  = "read_pipelines"
+	ScopeWritePipelines = "write_pipelines"
+	ScopeReadClusters   = "read_clusters"
+	ScopeWriteClusters  = "write_clusters"
+	ScopeGraphQL        = "graphql"
+)
+
+// AccessToken describes the API token the client authenticates with
+type AccessToken struct {
+	UUID   string   `json:"uuid"`
+	Scopes []string `json:"scopes"`
+}
+
+// GetAccessToken returns the API token the client authenticates with, including the scopes granted to it
+func (c *Client) GetAccessToken(ctx context.Context) (*AccessToken, error) {
+	token := AccessToken{}
+	if err := c.get(ctx, "/v2/access-token", &token); err != nil {
+		return nil, err
+	}
+
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+	c.token = &token
+
+	return &token, nil
+}
+
+// GrantedScopes returns the scopes granted to the API token, ok is false if they aren't known because
+// GetAccessToken hasn't been called
+func (c *Client) GrantedScopes() (scopes []string, ok bool) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	if c.token == nil {
+		return nil, false
+	}
+	return c.token.Scopes, true
+}
+
+// HasScope reports whether the token was granted the given scope
+func (t *AccessToken) HasScope(scope string) bool {
+	for _, granted := range t.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"github.com/machinebox/graphql"
+	"github.com/pkg/errors"
+	"log"
+)
+
+type agentTokenResponse struct {
+	AgentToken *AgentToken `json:"agentToken"`
+}
+
+type AgentToken struct {
+	Id          string `json:"id,omitempty"`
+	UUID        string `json:"uuid,omitempty"`
+	Description string `json:"description,omitempty"`
+	RevokedAt   string `json:"revokedAt,omitempty"`
+	// Token is the secret agents register with, the API only reveals it when the token is created
+	Token string `json:"-"`
+}
+
+type agentTokenCreateResponse struct {
+	AgentTokenCreate struct {
+		AgentTokenEdge struct {
+			Node AgentToken
+		}
+		TokenValue string `json:"tokenValue"`
+	}
+}
+
+type agentTokenRevokeResponse struct {
+	AgentTokenRevoke struct {
+		AgentToken AgentToken
+	}
+}
+
+func (c *Client) GetAgentToken(ctx context.Context, id string) (*AgentToken, error) {
+	log.Printf("[TRACE] Buildkite client GetAgentToken %s", id)
+
+	req := graphql.NewRequest(`
+query GetAgentToken($agentTokenId: ID!) {
+  agentToken: node(id: $agentTokenId) {
+    ... on AgentToken {
+      id
+      uuid
+      description
+      revokedAt
+    }
+  }
+}
+`)
+	req.Var("agentTokenId", id)
+
+	response := agentTokenResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to get agent token %s", id)
+	}
+	// the node is null if it does not exist and empty if the id belongs to a different type, a revoked token
+	// can't be used anymore so it is as good as deleted
+	if response.AgentToken == nil || response.AgentToken.Id == "" || response.AgentToken.RevokedAt != "" {
+		return nil, &NotFound{}
+	}
+
+	return response.AgentToken, nil
+}
+
+func (c *Client) CreateAgentToken(ctx context.Context, agentToken *AgentToken) (*AgentToken, error) {
+	log.Printf("[TRACE] Buildkite client CreateAgentToken %s", agentToken.Description)
+
+	orgId, err := c.GetOrganizationId(ctx, c.orgSlug)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch organization id")
+	}
+
+	req := graphql.NewRequest(`
+mutation AgentTokenCreateMutation($agentTokenCreateInput: AgentTokenCreateInput!) {
+  agentTokenCreate(input: $agentTokenCreateInput) {
+    agentTokenEdge {
+      node {
+        id
+        uuid
+        description
+        revokedAt
+      }
+    }
+    tokenValue
+  }
+}
+`)
+	req.Var("agentTokenCreateInput", map[string]interface{}{
+		"organizationID": orgId,
+		"description":    agentToken.Description,
+	})
+
+	response := agentTokenCreateResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to create agent token %s", agentToken.Description)
+	}
+
+	created := response.AgentTokenCreate.AgentTokenEdge.Node
+	created.Token = response.AgentTokenCreate.TokenValue
+	return &created, nil
+}
+
+// RevokeAgentToken revokes the agent token, agents which registered with it keep running but no new agents can
+// register with it. Revoked tokens can't be deleted.
+func (c *Client) RevokeAgentToken(ctx context.Context, id string, reason string) error {
+	log.Printf("[TRACE] Buildkite client RevokeAgentToken %s", id)
+
+	req := graphql.NewRequest(`
+mutation AgentTokenRevokeMutation($agentTokenRevokeInput: AgentTokenRevokeInput!) {
+  agentTokenRevoke(input: $agentTokenRevokeInput) {
+    agentToken {
+      id
+      revokedAt
+    }
+  }
+}
+`)
+	req.Var("agentTokenRevokeInput", map[string]interface{}{
+		"id":     id,
+		"reason": reason,
+	})
+
+	response := agentTokenRevokeResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return errors.Wrapf(err, "failed to revoke agent token %s", id)
+	}
+
+	return nil
+}
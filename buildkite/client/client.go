@@ -10,6 +10,7 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"sync"
 
 	"github.com/machinebox/graphql"
 	"github.com/pkg/errors"
@@ -25,50 +26,78 @@ const (
 )
 
 type Client struct {
-	client   *http.Client
-	graphQl  *graphql.Client
-	baseURL  *url.URL
-	orgSlug  string
-	apiToken string
+	client     *http.Client
+	graphQl    *graphql.Client
+	baseURL    *url.URL
+	orgSlug    string
+	apiToken   string
+	maxRetries int
+	stopCtx    context.Context
+	redactor   *redactor
+	nodeIDs    *nodeIDCache
+
+	// token is the API token as returned by GetAccessToken, the scopes granted to it are unknown until then
+	token      *AccessToken
+	tokenMutex sync.Mutex
 }
 
-func NewClient(orgSlug string, apiToken string) *Client {
-	var authTransport http.RoundTripper = NewAuthTransport(apiToken, userAgent+version.Version, nil)
-	baseURL, _ := url.Parse(defaultBaseURL)
+// NewClient creates a client for the REST and GraphQL API of the given organization. If opts is nil the public
+// Buildkite API is used.
+func NewClient(orgSlug string, apiToken string, opts *Options) (*Client, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	baseURL, err := opts.restAPIURL()
+	if err != nil {
+		return nil, err
+	}
+	graphQLURL, err := opts.graphQLAPIURL()
+	if err != nil {
+		return nil, err
+	}
+	transport, err := opts.transport()
+	if err != nil {
+		return nil, err
+	}
+	stopCtx := opts.StopContext
+	if stopCtx == nil {
+		stopCtx = context.Background()
+	}
+
+	var authTransport http.RoundTripper = NewAuthTransport(apiToken, userAgent+version.Version, &transport)
+	var retryTransport http.RoundTripper = newRetryTransport(authTransport, opts.MaxRetries)
+
+	redactor := newRedactor(opts.SensitiveFields)
 
 	return &Client{
 		client: &http.Client{
-			Transport: authTransport,
+			Transport: retryTransport,
 		},
-		graphQl: graphql.NewClient(defaultGraphQLUrl, graphql.WithHTTPClient(&http.Client{
-			Transport: authTransport,
+		graphQl: graphql.NewClient(graphQLURL, graphql.WithHTTPClient(&http.Client{
+			Transport: &graphQLTransport{retryTransport, redactor},
 		})),
-		baseURL:  baseURL,
-		orgSlug:  orgSlug,
-		apiToken: apiToken,
-	}
+		baseURL:    baseURL,
+		orgSlug:    orgSlug,
+		apiToken:   apiToken,
+		maxRetries: opts.MaxRetries,
+		stopCtx:    stopCtx,
+		redactor:   redactor,
+		nodeIDs:    newNodeIDCache(),
+	}, nil
 }
 
-func (c *Client) graphQLRequest(req *graphql.Request, result interface{}) error {
-	jsonBytes, _ := json.MarshalIndent(req, "", "  ")
-	log.Printf("[TRACE] GraphQL request %s", string(jsonBytes))
-
-	err := c.graphQl.Run(context.Background(), req, &result)
-	if err != nil {
-		log.Printf("[TRACE] GraphQL error %v", err)
-		return err
-	}
-
-	jsonBytes, _ = json.MarshalIndent(result, "", "  ")
-	log.Printf("[TRACE] GraphQL response %s", string(jsonBytes))
-	return nil
+// StopContext returns the context operations should derive their context from, so that they are aborted when
+// the client is asked to stop
+func (c *Client) StopContext() context.Context {
+	return c.stopCtx
 }
 
 func (c *Client) createOrgSlug(slug string) string {
 	return fmt.Sprintf("%s/%s", c.orgSlug, slug)
 }
 
-func marshalBody(body interface{}) (*bytes.Buffer, error) {
+func (c *Client) marshalBody(body interface{}) (*bytes.Buffer, error) {
 	if body == nil {
 		return nil, nil
 	}
@@ -77,17 +106,17 @@ func marshalBody(body interface{}) (*bytes.Buffer, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "could not marshal body")
 	}
-	log.Printf("[TRACE] Buildkite Request body %s\n", string(bodyBytes))
+	log.Printf("[TRACE] Buildkite Request body %s\n", c.redactor.body(bodyBytes))
 
 	return bytes.NewBuffer(bodyBytes), nil
 }
 
-func unmarshalResponse(body io.Reader, result interface{}) error {
+func (c *Client) unmarshalResponse(body io.Reader, result interface{}) error {
 	responseBytes, err := ioutil.ReadAll(body)
 	if err != nil {
 		return errors.Wrap(err, "could not read response body")
 	}
-	log.Printf("[TRACE] Buildkite Response body %s\n", string(responseBytes))
+	log.Printf("[TRACE] Buildkite Response body %s\n", c.redactor.body(responseBytes))
 
 	err = json.Unmarshal(responseBytes, result)
 	if err != nil {
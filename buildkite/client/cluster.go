@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// Cluster groups agents, queues and agent tokens, so that agents of one cluster only run the jobs of pipelines
+// in the same cluster
+type Cluster struct {
+	// Id is the UUID of the cluster, the REST API addresses clusters by it
+	Id             string `json:"id,omitempty"`
+	GraphQLId      string `json:"graphql_id,omitempty"`
+	DefaultQueueId string `json:"default_queue_id,omitempty"`
+	Name           string `json:"name,omitempty"`
+	Description    string `json:"description"`
+	Emoji          string `json:"emoji"`
+	Color          string `json:"color"`
+	Url            string `json:"url,omitempty"`
+	WebURL         string `json:"web_url,omitempty"`
+	CreatedAt      string `json:"created_at,omitempty"`
+}
+
+func (c *Client) GetCluster(ctx context.Context, id string) (*Cluster, error) {
+	log.Printf("[TRACE] Buildkite client GetCluster %s", id)
+
+	cluster := Cluster{}
+	relativePath := fmt.Sprintf("/v2/organizations/%s/clusters/%s", c.orgSlug, id)
+	if err := c.get(ctx, relativePath, &cluster); err != nil {
+		return nil, err
+	}
+
+	return &cluster, nil
+}
+
+func (c *Client) CreateCluster(ctx context.Context, cluster *Cluster) (*Cluster, error) {
+	log.Printf("[TRACE] Buildkite client CreateCluster %s", cluster.Name)
+
+	result := Cluster{}
+	relativePath := fmt.Sprintf("/v2/organizations/%s/clusters", c.orgSlug)
+	if err := c.post(ctx, relativePath, cluster, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) UpdateCluster(ctx context.Context, cluster *Cluster) (*Cluster, error) {
+	log.Printf("[TRACE] Buildkite client UpdateCluster %s", cluster.Id)
+
+	result := Cluster{}
+	relativePath := fmt.Sprintf("/v2/organizations/%s/clusters/%s", c.orgSlug, cluster.Id)
+	if err := c.patch(ctx, relativePath, cluster, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) DeleteCluster(ctx context.Context, id string) error {
+	log.Printf("[TRACE] Buildkite client DeleteCluster %s", id)
+
+	relativePath := fmt.Sprintf("/v2/organizations/%s/clusters/%s", c.orgSlug, id)
+	return c.delete(ctx, relativePath, nil)
+}
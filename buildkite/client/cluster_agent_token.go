@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ClusterAgentToken is a token agents of a cluster register with
+type ClusterAgentToken struct {
+	// Id is the UUID of the token, the REST API addresses tokens by it
+	Id          string
+	GraphQLId   string
+	ClusterId   string
+	Description string
+	// AllowedIPAddresses are the CIDR ranges agents may register from, any address is allowed if it is empty
+	AllowedIPAddresses []string
+	// Token is the secret agents register with, the API only reveals it when the token is created
+	Token     string
+	CreatedAt string
+}
+
+// clusterAgentTokenJSON is how the REST API represents a cluster agent token, it lists the allowed IP
+// addresses separated by spaces
+type clusterAgentTokenJSON struct {
+	Id                 string `json:"id"`
+	GraphQLId          string `json:"graphql_id"`
+	Description        string `json:"description"`
+	AllowedIPAddresses string `json:"allowed_ip_addresses"`
+	Token              string `json:"token"`
+	CreatedAt          string `json:"created_at"`
+}
+
+func (t *clusterAgentTokenJSON) toClusterAgentToken(clusterId string) *ClusterAgentToken {
+	return &ClusterAgentToken{
+		Id:                 t.Id,
+		GraphQLId:          t.GraphQLId,
+		ClusterId:          clusterId,
+		Description:        t.Description,
+		AllowedIPAddresses: strings.Fields(t.AllowedIPAddresses),
+		Token:              t.Token,
+		CreatedAt:          t.CreatedAt,
+	}
+}
+
+func (t *ClusterAgentToken) requestBody() map[string]interface{} {
+	return map[string]interface{}{
+		"description":          t.Description,
+		"allowed_ip_addresses": strings.Join(t.AllowedIPAddresses, " "),
+	}
+}
+
+func (c *Client) GetClusterAgentToken(ctx context.Context, clusterId string, id string) (*ClusterAgentToken, error) {
+	log.Printf("[TRACE] Buildkite client GetClusterAgentToken %s", id)
+
+	result := clusterAgentTokenJSON{}
+	relativePath := fmt.Sprintf("/v2/organizations/%s/clusters/%s/tokens/%s", c.orgSlug, clusterId, id)
+	if err := c.get(ctx, relativePath, &result); err != nil {
+		return nil, err
+	}
+
+	return result.toClusterAgentToken(clusterId), nil
+}
+
+func (c *Client) CreateClusterAgentToken(ctx context.Context, token *ClusterAgentToken) (*ClusterAgentToken, error) {
+	log.Printf("[TRACE] Buildkite client CreateClusterAgentToken %s", token.Description)
+
+	result := clusterAgentTokenJSON{}
+	relativePath := fmt.Sprintf("/v2/organizations/%s/clusters/%s/tokens", c.orgSlug, token.ClusterId)
+	if err := c.post(ctx, relativePath, token.requestBody(), &result); err != nil {
+		return nil, err
+	}
+
+	return result.toClusterAgentToken(token.ClusterId), nil
+}
+
+func (c *Client) UpdateClusterAgentToken(ctx context.Context, token *ClusterAgentToken) (*ClusterAgentToken, error) {
+	log.Printf("[TRACE] Buildkite client UpdateClusterAgentToken %s", token.Id)
+
+	result := clusterAgentTokenJSON{}
+	relativePath := fmt.Sprintf("/v2/organizations/%s/clusters/%s/tokens/%s", c.orgSlug, token.ClusterId, token.Id)
+	if err := c.patch(ctx, relativePath, token.requestBody(), &result); err != nil {
+		return nil, err
+	}
+
+	return result.toClusterAgentToken(token.ClusterId), nil
+}
+
+// RevokeClusterAgentToken revokes the token, agents which registered with it keep running but no new agents can
+// register with it
+func (c *Client) RevokeClusterAgentToken(ctx context.Context, clusterId string, id string) error {
+	log.Printf("[TRACE] Buildkite client RevokeClusterAgentToken %s", id)
+
+	relativePath := fmt.Sprintf("/v2/organizations/%s/clusters/%s/tokens/%s", c.orgSlug, clusterId, id)
+	return c.delete(ctx, relativePath, nil)
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// ClusterQueue is a queue of a cluster, jobs target it with the queue agent tag, e.g. queue=deploy
+type ClusterQueue struct {
+	// Id is the UUID of the queue, the REST API addresses queues by it
+	Id             string        `json:"id,omitempty"`
+	GraphQLId      string        `json:"graphql_id,omitempty"`
+	ClusterId      string        `json:"-"`
+	Key            string        `json:"key,omitempty"`
+	Description    string        `json:"description"`
+	DispatchPaused bool          `json:"dispatch_paused"`
+	HostedAgents   *HostedAgents `json:"hosted_agents,omitempty"`
+	WebURL         string        `json:"web_url,omitempty"`
+	CreatedAt      string        `json:"created_at,omitempty"`
+}
+
+// HostedAgents configures the agents Buildkite runs for a queue, a queue without it is served by self-hosted
+// agents
+type HostedAgents struct {
+	InstanceShape string
+	AgentImageRef string
+}
+
+type hostedAgentsJSON struct {
+	InstanceShape    json.RawMessage `json:"instance_shape,omitempty"`
+	PlatformSettings struct {
+		Linux struct {
+			AgentImageRef string `json:"agent_image_ref,omitempty"`
+		} `json:"linux"`
+	} `json:"platform_settings"`
+}
+
+func (h *HostedAgents) MarshalJSON() ([]byte, error) {
+	value := hostedAgentsJSON{}
+	value.InstanceShape, _ = json.Marshal(h.InstanceShape)
+	value.PlatformSettings.Linux.AgentImageRef = h.AgentImageRef
+	return json.Marshal(value)
+}
+
+// UnmarshalJSON accepts the instance shape both by name and as the object describing it, which responses use
+func (h *HostedAgents) UnmarshalJSON(data []byte) error {
+	value := hostedAgentsJSON{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	// a queue can have an agent image without choosing an instance shape
+	if len(value.InstanceShape) > 0 && string(value.InstanceShape) != "null" {
+		if err := json.Unmarshal(value.InstanceShape, &h.InstanceShape); err != nil {
+			var shape struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(value.InstanceShape, &shape); err != nil {
+				return err
+			}
+			h.InstanceShape = shape.Name
+		}
+	}
+	h.AgentImageRef = value.PlatformSettings.Linux.AgentImageRef
+
+	return nil
+}
+
+func (c *Client) GetClusterQueue(ctx context.Context, clusterId string, id string) (*ClusterQueue, error) {
+	log.Printf("[TRACE] Buildkite client GetClusterQueue %s", id)
+
+	queue := ClusterQueue{}
+	relativePath := fmt.Sprintf("/v2/organizations/%s/clusters/%s/queues/%s", c.orgSlug, clusterId, id)
+	if err := c.get(ctx, relativePath, &queue); err != nil {
+		return nil, err
+	}
+	// responses only link to the cluster of the queue
+	queue.ClusterId = clusterId
+
+	return &queue, nil
+}
+
+func (c *Client) CreateClusterQueue(ctx context.Context, queue *ClusterQueue) (*ClusterQueue, error) {
+	log.Printf("[TRACE] Buildkite client CreateClusterQueue %s", queue.Key)
+
+	result := ClusterQueue{}
+	relativePath := fmt.Sprintf("/v2/organizations/%s/clusters/%s/queues", c.orgSlug, queue.ClusterId)
+	body := map[string]interface{}{
+		"key":         queue.Key,
+		"description": queue.Description,
+	}
+	if queue.HostedAgents != nil {
+		body["hosted_agents"] = queue.HostedAgents
+	}
+	if err := c.post(ctx, relativePath, body, &result); err != nil {
+		return nil, err
+	}
+	result.ClusterId = queue.ClusterId
+
+	// queues are created with dispatch running, pausing it is a separate request
+	if queue.DispatchPaused {
+		return c.SetClusterQueueDispatchPaused(ctx, &result, true)
+	}
+
+	return &result, nil
+}
+
+func (c *Client) UpdateClusterQueue(ctx context.Context, queue *ClusterQueue) (*ClusterQueue, error) {
+	log.Printf("[TRACE] Buildkite client UpdateClusterQueue %s", queue.Id)
+
+	result := ClusterQueue{}
+	relativePath := fmt.Sprintf("/v2/organizations/%s/clusters/%s/queues/%s", c.orgSlug, queue.ClusterId, queue.Id)
+	// the key and whether agents are hosted can't be changed
+	body := map[string]interface{}{
+		"description": queue.Description,
+	}
+	if queue.HostedAgents != nil {
+		body["hosted_agents"] = queue.HostedAgents
+	}
+	if err := c.patch(ctx, relativePath, body, &result); err != nil {
+		return nil, err
+	}
+	result.ClusterId = queue.ClusterId
+
+	if result.DispatchPaused != queue.DispatchPaused {
+		return c.SetClusterQueueDispatchPaused(ctx, &result, queue.DispatchPaused)
+	}
+
+	return &result, nil
+}
+
+// SetClusterQueueDispatchPaused pauses or resumes dispatching jobs of the queue to agents
+func (c *Client) SetClusterQueueDispatchPaused(ctx context.Context, queue *ClusterQueue, paused bool) (*ClusterQueue, error) {
+	log.Printf("[TRACE] Buildkite client SetClusterQueueDispatchPaused %s %t", queue.Id, paused)
+
+	action := "resume_dispatch"
+	if paused {
+		action = "pause_dispatch"
+	}
+
+	result := ClusterQueue{}
+	relativePath := fmt.Sprintf("/v2/organizations/%s/clusters/%s/queues/%s/%s", c.orgSlug, queue.ClusterId, queue.Id, action)
+	if err := c.post(ctx, relativePath, nil, &result); err != nil {
+		return nil, err
+	}
+	result.ClusterId = queue.ClusterId
+
+	return &result, nil
+}
+
+func (c *Client) DeleteClusterQueue(ctx context.Context, clusterId string, id string) error {
+	log.Printf("[TRACE] Buildkite client DeleteClusterQueue %s", id)
+
+	relativePath := fmt.Sprintf("/v2/organizations/%s/clusters/%s/queues/%s", c.orgSlug, clusterId, id)
+	return c.delete(ctx, relativePath, nil)
+}
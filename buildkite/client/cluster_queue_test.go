@@ -0,0 +1,31 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestHostedAgentsUnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		name          string
+		body          string
+		instanceShape string
+		agentImageRef string
+	}{
+		{"shape object", `{"instance_shape":{"name":"LINUX_AMD64_2X4"},"platform_settings":{"linux":{"agent_image_ref":"ubuntu"}}}`, "LINUX_AMD64_2X4", "ubuntu"},
+		{"shape name", `{"instance_shape":"LINUX_AMD64_2X4"}`, "LINUX_AMD64_2X4", ""},
+		{"only agent image", `{"platform_settings":{"linux":{"agent_image_ref":"ubuntu"}}}`, "", "ubuntu"},
+		{"null shape", `{"instance_shape":null,"platform_settings":{"linux":{"agent_image_ref":"ubuntu"}}}`, "", "ubuntu"},
+	}
+
+	for _, c := range cases {
+		hostedAgents := HostedAgents{}
+		if err := json.Unmarshal([]byte(c.body), &hostedAgents); err != nil {
+			t.Errorf("%s: %s", c.name, err)
+			continue
+		}
+		if hostedAgents.InstanceShape != c.instanceShape || hostedAgents.AgentImageRef != c.agentImageRef {
+			t.Errorf("%s: expected %q and %q, got %+v", c.name, c.instanceShape, c.agentImageRef, hostedAgents)
+		}
+	}
+}
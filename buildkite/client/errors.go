@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const requestIDHeader = "X-Request-Id"
+
+// APIError is returned when the Buildkite API rejects a request, it carries enough detail to tell authentication
+// and permission problems apart from validation errors
+type APIError struct {
+	// StatusCode is the HTTP status of the response, GraphQL errors are usually reported with 200 OK
+	StatusCode int
+	// RequestID identifies the request when reporting a problem to Buildkite support
+	RequestID string
+	Method    string
+	Endpoint  string
+	Message   string
+	Errors    []FieldError
+}
+
+// FieldError is a single error reported by the API, Field is empty if it doesn't concern a specific attribute
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (err *APIError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s %s failed with status %d", err.Method, err.Endpoint, err.StatusCode)
+	if err.RequestID != "" {
+		fmt.Fprintf(&b, " (request ID %s)", err.RequestID)
+	}
+	if err.Message != "" {
+		fmt.Fprintf(&b, ": %s", err.Message)
+	}
+	for _, fieldErr := range err.Errors {
+		fmt.Fprintf(&b, "\n  %s", fieldErr)
+	}
+
+	return b.String()
+}
+
+func (err FieldError) String() string {
+	if err.Field == "" {
+		return err.Message
+	}
+	return err.Field + ": " + err.Message
+}
+
+// IsAuthError reports whether the API token is invalid or lacks the scopes or permissions for the request
+func (err *APIError) IsAuthError() bool {
+	return err.StatusCode == http.StatusUnauthorized || err.StatusCode == http.StatusForbidden
+}
+
+// IsValidationError reports whether the request was rejected because of the values it contained
+func (err *APIError) IsValidationError() bool {
+	return err.StatusCode == http.StatusUnprocessableEntity
+}
+
+// restErrorResponse is the body of a failed REST request, e.g.
+// {"message": "Validation Failed", "errors": [{"field": "name", "code": "already_exists"}]}
+type restErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []json.RawMessage `json:"errors"`
+}
+
+type restFieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// newRESTError creates an APIError from a non-2xx REST response, body is the already read response body
+func newRESTError(resp *http.Response, body []byte) *APIError {
+	apiErr := newAPIError(resp)
+
+	var parsed restErrorResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		apiErr.Message = strings.TrimSpace(string(body))
+		return apiErr
+	}
+
+	apiErr.Message = parsed.Message
+	for _, raw := range parsed.Errors {
+		var fieldErr restFieldError
+		if err := json.Unmarshal(raw, &fieldErr); err != nil {
+			// some endpoints report plain strings instead of objects
+			var message string
+			if err := json.Unmarshal(raw, &message); err == nil {
+				apiErr.Errors = append(apiErr.Errors, FieldError{Message: message})
+			}
+			continue
+		}
+
+		message := fieldErr.Message
+		if message == "" {
+			message = strings.Replace(fieldErr.Code, "_", " ", -1)
+		}
+		apiErr.Errors = append(apiErr.Errors, FieldError{Field: fieldErr.Field, Message: message})
+	}
+
+	return apiErr
+}
+
+// graphQLErrorResponse holds the errors of a GraphQL response, Buildkite names the offending input in the
+// extensions of an error where it can
+type graphQLErrorResponse struct {
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// newGraphQLError creates an APIError from the response the GraphQL client received, message is the error the
+// client reported and is used when the response can't be parsed
+func newGraphQLError(recorded *recordedResponse, message string) *APIError {
+	apiErr := newAPIError(recorded.response)
+	apiErr.Message = message
+
+	var parsed graphQLErrorResponse
+	if err := json.Unmarshal(recorded.body, &parsed); err != nil || len(parsed.Errors) == 0 {
+		return apiErr
+	}
+
+	if len(parsed.Errors) == 1 {
+		apiErr.Message = parsed.Errors[0].Message
+	} else {
+		apiErr.Message = fmt.Sprintf("%d errors", len(parsed.Errors))
+	}
+	for _, graphQLErr := range parsed.Errors {
+		field, _ := graphQLErr.Extensions["field"].(string)
+		if field == "" && len(parsed.Errors) == 1 {
+			continue
+		}
+		apiErr.Errors = append(apiErr.Errors, FieldError{Field: field, Message: graphQLErr.Message})
+	}
+
+	return apiErr
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get(requestIDHeader),
+		Method:     resp.Request.Method,
+		Endpoint:   resp.Request.URL.String(),
+	}
+}
+
+// recordedResponse keeps the final response of a request, so that errors reported by the GraphQL client, which
+// only exposes a message, can be enriched with the status, request ID and body of the response
+type recordedResponse struct {
+	response *http.Response
+	body     []byte
+}
+
+type recordedResponseKey struct{}
+
+func withRecordedResponse(ctx context.Context) (context.Context, *recordedResponse) {
+	recorded := &recordedResponse{}
+	return context.WithValue(ctx, recordedResponseKey{}, recorded), recorded
+}
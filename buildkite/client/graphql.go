@@ -0,0 +1,100 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/machinebox/graphql"
+)
+
+const graphQLErrorPrefix = "graphql: "
+
+// GraphQL reports a missing object either with an error or by resolving it to null, e.g.
+// "No pipeline found with slug ...", "No pipeline template found ..." or "Couldn't find TeamMember with ..."
+var graphQLNotFoundMessage = regexp.MustCompile(`(?i)^(no [\w ]+ found|couldn't find|not found)`)
+
+func (c *Client) graphQLRequest(ctx context.Context, req *graphql.Request, result interface{}) error {
+	// the request itself is logged by graphQLTransport, since the GraphQL client doesn't expose its body
+	for attempt := 0; ; attempt++ {
+		attemptCtx, recorded := withRecordedResponse(ctx)
+		err := c.graphQl.Run(attemptCtx, req, &result)
+		if err == nil {
+			break
+		}
+		log.Printf("[TRACE] GraphQL error %v", err)
+
+		// HTTP level failures are retried by the transport, but the GraphQL API can also reject a query
+		// because of its rate limit in an otherwise successful response
+		if attempt >= c.maxRetries || !isGraphQLRateLimitError(err) {
+			return toGraphQLError(err, recorded)
+		}
+
+		wait := backoff(attempt)
+		log.Printf("[DEBUG] GraphQL rate limit exceeded, retrying in %s (%d/%d)", wait, attempt+1, c.maxRetries)
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[TRACE] GraphQL response %s", c.redactor.value(result))
+	return nil
+}
+
+// toGraphQLError converts the errors reported by the GraphQL API into typed errors, errors from the transport
+// are returned as they are
+func toGraphQLError(err error, recorded *recordedResponse) error {
+	if recorded.response == nil {
+		return err
+	}
+	message := strings.TrimPrefix(err.Error(), graphQLErrorPrefix)
+
+	if graphQLNotFoundMessage.MatchString(message) {
+		log.Printf("[DEBUG] GraphQL object not found: %s", message)
+		return &NotFound{}
+	}
+
+	return newGraphQLError(recorded, message)
+}
+
+func isGraphQLRateLimitError(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "rate limit") || strings.Contains(message, "too many requests")
+}
+
+// graphQLTransport logs GraphQL requests and records the response of requests whose context asks for it
+type graphQLTransport struct {
+	transport http.RoundTripper
+	redactor  *redactor
+}
+
+func (t *graphQLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			bodyBytes, _ := ioutil.ReadAll(body)
+			log.Printf("[TRACE] GraphQL request %s\nHeaders %v", t.redactor.body(bodyBytes), t.redactor.header(req.Header))
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+
+	recorded, ok := req.Context().Value(recordedResponseKey{}).(*recordedResponse)
+	if !ok || err != nil {
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	recorded.response = resp
+	recorded.body = body
+	return resp, nil
+}
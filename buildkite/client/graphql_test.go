@@ -0,0 +1,95 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestToGraphQLError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      string
+		body     string
+		notFound bool
+		message  string
+	}{
+		{
+			name:     "no object found",
+			err:      "graphql: No pipeline found with slug foo",
+			notFound: true,
+		},
+		{
+			name:     "no object with a multi word type found",
+			err:      "graphql: No pipeline template found with ID bar",
+			notFound: true,
+		},
+		{
+			name:     "couldn't find",
+			err:      "graphql: Couldn't find TeamMember with 'uuid'=baz",
+			notFound: true,
+		},
+		{
+			name:     "not found",
+			err:      "graphql: Not Found",
+			notFound: true,
+		},
+		{
+			name:    "found in the middle of a message",
+			err:     "graphql: Pipeline template not found, so it can't be assigned",
+			message: "Pipeline template not found, so it can't be assigned",
+		},
+		{
+			name:    "validation error",
+			err:     "graphql: Name can't be blank",
+			body:    `{"errors": [{"message": "Name can't be blank", "extensions": {"field": "name"}}]}`,
+			message: "Name can't be blank",
+		},
+		{
+			name:    "unparsed body",
+			err:     "graphql: Something went wrong",
+			body:    "<html></html>",
+			message: "Something went wrong",
+		},
+	}
+
+	endpoint, _ := url.Parse("https://graphql.buildkite.com/v1")
+	for _, c := range cases {
+		recorded := &recordedResponse{
+			response: &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Request:    &http.Request{Method: http.MethodPost, URL: endpoint},
+			},
+			body: []byte(c.body),
+		}
+
+		err := toGraphQLError(errors.New(c.err), recorded)
+
+		var notFound *NotFound
+		if errors.As(err, &notFound) != c.notFound {
+			t.Errorf("%s: expected not found to be %t, got %v", c.name, c.notFound, err)
+			continue
+		}
+		if c.notFound {
+			continue
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("%s: expected an APIError, got %v", c.name, err)
+			continue
+		}
+		if apiErr.Message != c.message {
+			t.Errorf("%s: expected message %q, got %q", c.name, c.message, apiErr.Message)
+		}
+	}
+}
+
+func TestToGraphQLError_noResponse(t *testing.T) {
+	err := errors.New("dial tcp: connection refused")
+	if converted := toGraphQLError(err, &recordedResponse{}); converted != err {
+		t.Errorf("expected the error of a failed request to be returned as is, got %v", converted)
+	}
+}
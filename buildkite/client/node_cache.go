@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// nodeIDCache remembers the GraphQL ids of objects which are looked up by their slug, since many resources need
+// the id of the same organization or pipeline during one run. Keys are qualified by the type of the object and
+// the organization, see organizationKey and pipelineKey.
+type nodeIDCache struct {
+	mutex sync.Mutex
+	ids   map[string]string
+}
+
+func newNodeIDCache() *nodeIDCache {
+	return &nodeIDCache{ids: map[string]string{}}
+}
+
+func organizationKey(orgSlug string) string {
+	return "organization:" + orgSlug
+}
+
+func pipelineKey(orgSlug string, slug string) string {
+	return "pipeline:" + orgSlug + "/" + slug
+}
+
+// lookup returns the cached id for key, or calls fetch and caches its result. Concurrent lookups of an id
+// which isn't cached yet may fetch it more than once, but never block each other on a slow request.
+func (cache *nodeIDCache) lookup(ctx context.Context, key string, fetch func(ctx context.Context) (string, error)) (string, error) {
+	cache.mutex.Lock()
+	id, ok := cache.ids[key]
+	cache.mutex.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	id, err := fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	cache.set(key, id)
+	return id, nil
+}
+
+func (cache *nodeIDCache) set(key string, id string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.ids[key] = id
+}
+
+// forget drops the id of an object which was deleted or renamed, so that its slug can be reused
+func (cache *nodeIDCache) forget(key string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	delete(cache.ids, key)
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNodeIDCacheLookup(t *testing.T) {
+	cache := newNodeIDCache()
+	fetches := 0
+	fetch := func(id string) func(context.Context) (string, error) {
+		return func(context.Context) (string, error) {
+			fetches++
+			return id, nil
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		id, err := cache.lookup(context.Background(), organizationKey("acme"), fetch("acme-id"))
+		if err != nil || id != "acme-id" {
+			t.Fatalf("expected acme-id, got %q (%v)", id, err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("expected the id to be fetched once, fetched %d times", fetches)
+	}
+
+	id, _ := cache.lookup(context.Background(), organizationKey("other"), fetch("other-id"))
+	if id != "other-id" {
+		t.Errorf("expected organizations to be cached separately, got %q", id)
+	}
+
+	cache.forget(organizationKey("acme"))
+	cache.lookup(context.Background(), organizationKey("acme"), fetch("acme-id"))
+	if fetches != 3 {
+		t.Errorf("expected a forgotten id to be fetched again, fetched %d times", fetches)
+	}
+}
+
+func TestNodeIDCacheLookupError(t *testing.T) {
+	cache := newNodeIDCache()
+
+	_, err := cache.lookup(context.Background(), pipelineKey("acme", "deploy"), func(context.Context) (string, error) {
+		return "", &NotFound{}
+	})
+	var notFound *NotFound
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+
+	if _, ok := cache.ids[pipelineKey("acme", "deploy")]; ok {
+		t.Errorf("expected failed lookups not to be cached")
+	}
+}
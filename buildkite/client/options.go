@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Options configures how the client connects to Buildkite. The zero value talks to the public Buildkite API.
+type Options struct {
+	// RestAPIURL is the base URL of the REST API, defaults to https://api.buildkite.com/
+	RestAPIURL string
+	// GraphQLAPIURL is the GraphQL endpoint, defaults to https://graphql.buildkite.com/v1
+	GraphQLAPIURL string
+	// HTTPProxy is the URL of the proxy all requests are sent through. If empty, the proxy is taken from
+	// the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
+	HTTPProxy string
+	// CACertFile is a PEM encoded bundle of certificate authorities which are trusted in addition to the
+	// system certificate pool
+	CACertFile string
+	// InsecureSkipVerify disables TLS certificate verification
+	InsecureSkipVerify bool
+	// SensitiveFields are fields of request and response bodies whose values are masked in the log, in addition to
+	// well known secrets such as tokens and environment variables
+	SensitiveFields []string
+	// MaxRetries is how often a request failing with a transient error is retried
+	MaxRetries int
+	// StopContext is cancelled when the caller wants all outstanding operations to be aborted, e.g. when
+	// Terraform asks the provider to stop. Defaults to context.Background().
+	StopContext context.Context
+}
+
+func (o *Options) restAPIURL() (*url.URL, error) {
+	rawURL := o.RestAPIURL
+	if rawURL == "" {
+		rawURL = defaultBaseURL
+	}
+	// relative paths are resolved against the base URL, so it needs to end with a slash to keep its path
+	if !strings.HasSuffix(rawURL, "/") {
+		rawURL += "/"
+	}
+
+	baseURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid REST API URL %s", o.RestAPIURL)
+	}
+	return baseURL, nil
+}
+
+func (o *Options) graphQLAPIURL() (string, error) {
+	if o.GraphQLAPIURL == "" {
+		return defaultGraphQLUrl, nil
+	}
+
+	if _, err := url.Parse(o.GraphQLAPIURL); err != nil {
+		return "", errors.Wrapf(err, "invalid GraphQL API URL %s", o.GraphQLAPIURL)
+	}
+	return o.GraphQLAPIURL, nil
+}
+
+// transport builds the transport which is wrapped by the auth transport
+func (o *Options) transport() (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if o.HTTPProxy != "" {
+		proxyURL, err := url.Parse(o.HTTPProxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid HTTP proxy %s", o.HTTPProxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if o.CACertFile == "" && !o.InsecureSkipVerify {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CACertFile != "" {
+		pem, err := ioutil.ReadFile(o.CACertFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read CA certificate file %s", o.CACertFile)
+		}
+
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no PEM encoded certificates found in %s", o.CACertFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
@@ -1,9 +1,11 @@
 package client
 
 import (
+	"context"
 	"github.com/machinebox/graphql"
 	"github.com/pkg/errors"
 	"log"
+	"strings"
 )
 
 const (
@@ -12,7 +14,7 @@ const (
 )
 
 type organizationMemberResponse struct {
-	OrgMember OrganizationMember `json:"organizationMember"`
+	OrgMember *OrganizationMember `json:"organizationMember"`
 }
 
 type organizationMemberUpdateResponse struct {
@@ -39,7 +41,7 @@ type User struct {
 	Email string `json:"email,omitempty"`
 }
 
-func (c *Client) GetOrganizationMember(uuid string) (*OrganizationMember, error) {
+func (c *Client) GetOrganizationMember(ctx context.Context, uuid string) (*OrganizationMember, error) {
 	log.Printf("[TRACE] Buildkite client GetOrganizationMember %s", uuid)
 
 	req := graphql.NewRequest(`
@@ -59,14 +61,17 @@ query GetOrganizationMember($orgMemberSlug: ID!) {
 	req.Var("orgMemberSlug", c.createOrgSlug(uuid))
 
 	orgMemberResponse := organizationMemberResponse{}
-	if err := c.graphQLRequest(req, &orgMemberResponse); err != nil {
+	if err := c.graphQLRequest(ctx, req, &orgMemberResponse); err != nil {
 		return nil, errors.Wrapf(err, "failed to get organization member %s", uuid)
 	}
+	if orgMemberResponse.OrgMember == nil {
+		return nil, &NotFound{}
+	}
 
-	return &orgMemberResponse.OrgMember, nil
+	return orgMemberResponse.OrgMember, nil
 }
 
-func (c *Client) UpdateOrganizationMember(orgMember *OrganizationMember) (*OrganizationMember, error) {
+func (c *Client) UpdateOrganizationMember(ctx context.Context, orgMember *OrganizationMember) (*OrganizationMember, error) {
 	log.Printf("[TRACE] Buildkite client UpdateOrganizationMember %s", orgMember.Id)
 
 	req := graphql.NewRequest(`
@@ -93,14 +98,14 @@ mutation OrganizationMemberUpdateMutation($organizationMemberUpdateInput: Organi
 	})
 
 	orgMemberUpdateResponse := organizationMemberUpdateResponse{}
-	if err := c.graphQLRequest(req, &orgMemberUpdateResponse); err != nil {
+	if err := c.graphQLRequest(ctx, req, &orgMemberUpdateResponse); err != nil {
 		return nil, errors.Wrapf(err, "failed to update organization member %s", orgMember.Id)
 	}
 
 	return &orgMemberUpdateResponse.OrganizationMemberUpdate.OrganizationMember, nil
 }
 
-func (c *Client) DeleteOrganizationMember(id string) error {
+func (c *Client) DeleteOrganizationMember(ctx context.Context, id string) error {
 	log.Printf("[TRACE] Buildkite client DeleteOrganizationMember %s", id)
 	req := graphql.NewRequest(`
 mutation OrganizationMemberDeleteMutation($organizationMemberDeleteInput: OrganizationMemberDeleteInput!) {
@@ -115,9 +120,118 @@ mutation OrganizationMemberDeleteMutation($organizationMemberDeleteInput: Organi
 	})
 
 	orgMemberDeleteResponse := organizationMemberDeleteResponse{}
-	if err := c.graphQLRequest(req, &orgMemberDeleteResponse); err != nil {
+	if err := c.graphQLRequest(ctx, req, &orgMemberDeleteResponse); err != nil {
 		return errors.Wrapf(err, "failed to delete organization member %s", id)
 	}
 
 	return nil
 }
+
+// GetOrganizationMemberByUserId returns the membership of the user in the organization, memberships can only be looked
+// up by their UUID so the members of the organization are searched for it
+func (c *Client) GetOrganizationMemberByUserId(ctx context.Context, userId string) (*OrganizationMember, error) {
+	log.Printf("[TRACE] Buildkite client GetOrganizationMemberByUserId %s", userId)
+
+	members, err := c.findOrganizationMembers(ctx, "", func(member *OrganizationMember) bool {
+		return member.User.Id == userId
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to look up the organization membership of user %s", userId)
+	}
+	if len(members) == 0 {
+		return nil, &NotFound{}
+	}
+
+	return &members[0], nil
+}
+
+// GetOrganizationMemberByEmail returns the membership of the user with the email address, it fails unless exactly one
+// member of the organization has it
+func (c *Client) GetOrganizationMemberByEmail(ctx context.Context, email string) (*OrganizationMember, error) {
+	log.Printf("[TRACE] Buildkite client GetOrganizationMemberByEmail %s", email)
+
+	// the search also matches names and parts of email addresses, only exact matches count
+	members, err := c.findOrganizationMembers(ctx, email, func(member *OrganizationMember) bool {
+		return strings.EqualFold(member.User.Email, email)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to look up the organization member with email %s", email)
+	}
+
+	switch len(members) {
+	case 0:
+		return nil, errors.Errorf("no member of organization %s has the email %s", c.orgSlug, email)
+	case 1:
+		return &members[0], nil
+	default:
+		uuids := make([]string, len(members))
+		for i, member := range members {
+			uuids[i] = member.UUID
+		}
+		return nil, errors.Errorf("%d members of organization %s have the email %s, use the uuid of the membership "+
+			"instead: %s", len(members), c.orgSlug, email, strings.Join(uuids, ", "))
+	}
+}
+
+// findOrganizationMembers returns the members of the organization found by search which match, an empty search returns
+// all of them
+func (c *Client) findOrganizationMembers(ctx context.Context, search string, match func(*OrganizationMember) bool) ([]OrganizationMember, error) {
+	query := `
+query GetOrganizationMembers($orgSlug: ID!, $search: String, $first: Int!, $after: String) {
+  organization(slug: $orgSlug) {
+    members(search: $search, first: $first, after: $after) {
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+      edges {
+        node {
+          id
+          uuid
+          role
+          createdAt
+          user {
+            id
+            name
+            email
+          }
+        }
+      }
+    }
+  }
+}`
+
+	vars := map[string]interface{}{"orgSlug": c.orgSlug}
+	if search != "" {
+		vars["search"] = search
+	}
+
+	members := []OrganizationMember{}
+	err := c.paginate(ctx, query, vars, func(req *graphql.Request) (*pageInfo, error) {
+		var resp struct {
+			Organization struct {
+				Members struct {
+					PageInfo pageInfo `json:"pageInfo"`
+					Edges    []struct {
+						Node OrganizationMember `json:"node"`
+					} `json:"edges"`
+				} `json:"members"`
+			} `json:"organization"`
+		}
+		if err := c.graphQLRequest(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, edge := range resp.Organization.Members.Edges {
+			if match(&edge.Node) {
+				members = append(members, edge.Node)
+			}
+		}
+		return &resp.Organization.Members.PageInfo, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
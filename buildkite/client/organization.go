@@ -1,8 +1,8 @@
 package client
 
 import (
+	"context"
 	"github.com/machinebox/graphql"
-	"sync"
 )
 
 type orgIdResponse struct {
@@ -14,41 +14,31 @@ type Node struct {
 	Slug string `json:"Slug,omitempty"`
 }
 
-var (
-	orgIds   = map[string]string{}
-	orgMutex = &sync.Mutex{}
-)
-
-func (c *Client) GetOrganizationId(slug string) (string, error) {
-	orgMutex.Lock()
-	defer orgMutex.Unlock()
-
-	if val, ok := orgIds[slug]; ok {
-		return val, nil
-	}
-
-	val, err := c.fetchOrganizationId(slug)
-	if err != nil {
-		return "", err
-	}
-
-	orgIds[slug] = val
-	return val, nil
+// GetOrganizationId returns the GraphQL id of the organization with the given slug, it is only looked up once
+// per client
+func (c *Client) GetOrganizationId(ctx context.Context, slug string) (string, error) {
+	return c.nodeIDs.lookup(ctx, organizationKey(slug), func(ctx context.Context) (string, error) {
+		return c.fetchOrganizationId(ctx, slug)
+	})
 }
 
-func (c *Client) fetchOrganizationId(slug string) (string, error) {
+func (c *Client) fetchOrganizationId(ctx context.Context, slug string) (string, error) {
 	req := graphql.NewRequest(`
 query Organization($orgSlug: ID!) {
   organization(slug: $orgSlug) {
     id
   }
 }`)
-	req.Var("orgSlug", c.orgSlug)
+	req.Var("orgSlug", slug)
 
 	idResponse := orgIdResponse{}
-	if err := c.graphQLRequest(req, &idResponse); err != nil {
+	if err := c.graphQLRequest(ctx, req, &idResponse); err != nil {
 		return "", err
 	}
 
+	if idResponse.Organization.Id == "" {
+		return "", &NotFound{}
+	}
+
 	return idResponse.Organization.Id, nil
 }
@@ -0,0 +1,241 @@
+package client
+
+import (
+	"context"
+	"github.com/machinebox/graphql"
+	"github.com/pkg/errors"
+	"log"
+)
+
+const (
+	OrganizationInvitationStatePending  = "PENDING"
+	OrganizationInvitationStateAccepted = "ACCEPTED"
+	OrganizationInvitationStateExpired  = "EXPIRED"
+	OrganizationInvitationStateRevoked  = "REVOKED"
+)
+
+type organizationInvitationResponse struct {
+	OrganizationInvitation *OrganizationInvitation `json:"organizationInvitation"`
+}
+
+// OrganizationInvitation invites a person to join the organization by email, they become a member once they accept
+// it
+type OrganizationInvitation struct {
+	Id         string                 `json:"id,omitempty"`
+	UUID       string                 `json:"uuid,omitempty"`
+	Email      string                 `json:"email,omitempty"`
+	Role       string                 `json:"role,omitempty"`
+	State      string                 `json:"state,omitempty"`
+	CreatedAt  string                 `json:"createdAt,omitempty"`
+	AcceptedAt string                 `json:"acceptedAt,omitempty"`
+	AcceptedBy *User                  `json:"acceptedBy,omitempty"`
+	Teams      []InvitationTeamAccess `json:"-"`
+}
+
+// InvitationTeamAccess is a team the invited person joins when they accept the invitation
+type InvitationTeamAccess struct {
+	TeamId string
+	Role   string
+}
+
+type organizationInvitationCreateResponse struct {
+	OrganizationInvitationCreate struct {
+		InvitationEdges []struct {
+			Node OrganizationInvitation
+		}
+	}
+}
+
+type organizationInvitationRevokeResponse struct {
+	OrganizationInvitationRevoke struct {
+		OrganizationInvitation OrganizationInvitation
+	}
+}
+
+func (c *Client) GetOrganizationInvitation(ctx context.Context, id string) (*OrganizationInvitation, error) {
+	log.Printf("[TRACE] Buildkite client GetOrganizationInvitation %s", id)
+
+	req := graphql.NewRequest(`
+query GetOrganizationInvitation($organizationInvitationId: ID!) {
+  organizationInvitation: node(id: $organizationInvitationId) {
+    ... on OrganizationInvitation {
+      id
+      uuid
+      email
+      role
+      state
+      createdAt
+      acceptedAt
+      acceptedBy {
+        id
+        name
+        email
+      }
+    }
+  }
+}
+`)
+	req.Var("organizationInvitationId", id)
+
+	response := organizationInvitationResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to get organization invitation %s", id)
+	}
+	// the node is null if it does not exist and empty if the id belongs to a different type
+	if response.OrganizationInvitation == nil || response.OrganizationInvitation.Id == "" {
+		return nil, &NotFound{}
+	}
+
+	teams, err := c.getOrganizationInvitationTeams(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	response.OrganizationInvitation.Teams = teams
+
+	return response.OrganizationInvitation, nil
+}
+
+// CreateOrganizationInvitation sends an invitation to the email address of the invitation, Buildkite emails the
+// invited person a link to accept it
+func (c *Client) CreateOrganizationInvitation(ctx context.Context, invitation *OrganizationInvitation) (*OrganizationInvitation, error) {
+	log.Printf("[TRACE] Buildkite client CreateOrganizationInvitation %s", invitation.Email)
+
+	orgId, err := c.GetOrganizationId(ctx, c.orgSlug)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch organization id")
+	}
+
+	req := graphql.NewRequest(`
+mutation OrganizationInvitationCreateMutation($organizationInvitationCreateInput: OrganizationInvitationCreateInput!) {
+  organizationInvitationCreate(input: $organizationInvitationCreateInput) {
+    invitationEdges {
+      node {
+        id
+        uuid
+        email
+        role
+        state
+        createdAt
+        acceptedAt
+      }
+    }
+  }
+}
+`)
+
+	teams := []map[string]string{}
+	for _, team := range invitation.Teams {
+		teams = append(teams, map[string]string{
+			"id":   team.TeamId,
+			"role": team.Role,
+		})
+	}
+	req.Var("organizationInvitationCreateInput", map[string]interface{}{
+		"organizationID": orgId,
+		"emails":         []string{invitation.Email},
+		"role":           invitation.Role,
+		"teams":          teams,
+	})
+
+	response := organizationInvitationCreateResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to invite %s", invitation.Email)
+	}
+	if len(response.OrganizationInvitationCreate.InvitationEdges) != 1 {
+		return nil, errors.Errorf("expected a single invitation for %s, got %d", invitation.Email,
+			len(response.OrganizationInvitationCreate.InvitationEdges))
+	}
+
+	created := &response.OrganizationInvitationCreate.InvitationEdges[0].Node
+	created.Teams, err = c.getOrganizationInvitationTeams(ctx, created.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// getOrganizationInvitationTeams returns the teams the invited person joins when they accept the invitation, they are
+// read with their own query so that the connection can be paginated
+func (c *Client) getOrganizationInvitationTeams(ctx context.Context, id string) ([]InvitationTeamAccess, error) {
+	query := `
+query GetOrganizationInvitationTeams($organizationInvitationId: ID!, $first: Int!, $after: String) {
+  organizationInvitation: node(id: $organizationInvitationId) {
+    ... on OrganizationInvitation {
+      teams(first: $first, after: $after) {
+        pageInfo {
+          hasNextPage
+          endCursor
+        }
+        edges {
+          node {
+            role
+            team {
+              id
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+	teams := []InvitationTeamAccess{}
+	err := c.paginate(ctx, query, map[string]interface{}{"organizationInvitationId": id}, func(req *graphql.Request) (*pageInfo, error) {
+		var resp struct {
+			OrganizationInvitation *struct {
+				Teams struct {
+					PageInfo pageInfo `json:"pageInfo"`
+					Edges    []struct {
+						Node struct {
+							Role string `json:"role"`
+							Team Node   `json:"team"`
+						} `json:"node"`
+					} `json:"edges"`
+				} `json:"teams"`
+			} `json:"organizationInvitation"`
+		}
+		if err := c.graphQLRequest(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		if resp.OrganizationInvitation == nil {
+			return nil, &NotFound{}
+		}
+
+		for _, edge := range resp.OrganizationInvitation.Teams.Edges {
+			teams = append(teams, InvitationTeamAccess{TeamId: edge.Node.Team.Id, Role: edge.Node.Role})
+		}
+		return &resp.OrganizationInvitation.Teams.PageInfo, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the teams of organization invitation %s", id)
+	}
+
+	return teams, nil
+}
+
+// RevokeOrganizationInvitation revokes a pending invitation, it can't be accepted anymore
+func (c *Client) RevokeOrganizationInvitation(ctx context.Context, id string) error {
+	log.Printf("[TRACE] Buildkite client RevokeOrganizationInvitation %s", id)
+
+	req := graphql.NewRequest(`
+mutation OrganizationInvitationRevokeMutation($organizationInvitationRevokeInput: OrganizationInvitationRevokeInput!) {
+  organizationInvitationRevoke(input: $organizationInvitationRevokeInput) {
+    organizationInvitation {
+      id
+      state
+    }
+  }
+}
+`)
+	req.Var("organizationInvitationRevokeInput", map[string]interface{}{
+		"id": id,
+	})
+
+	response := organizationInvitationRevokeResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return errors.Wrapf(err, "failed to revoke organization invitation %s", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+
+	"github.com/machinebox/graphql"
+)
+
+// pageSize is the number of nodes requested per page of a GraphQL connection
+const pageSize = 100
+
+// pageInfo is the pagination state of a GraphQL connection
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// pageFunc runs the request for a single page, collects its nodes and returns the page info of the connection
+type pageFunc func(req *graphql.Request) (*pageInfo, error)
+
+// paginate follows a GraphQL connection until all of its pages have been fetched. The query has to declare the
+// variables $first: Int! and $after: String and pass them to the connection, e.g. teams(first: $first, after: $after),
+// and select pageInfo { hasNextPage endCursor } on it.
+func (c *Client) paginate(ctx context.Context, query string, vars map[string]interface{}, page pageFunc) error {
+	var cursor *string
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		req := graphql.NewRequest(query)
+		for key, value := range vars {
+			req.Var(key, value)
+		}
+		req.Var("first", pageSize)
+		req.Var("after", cursor)
+
+		info, err := page(req)
+		if err != nil {
+			return err
+		}
+		// a missing cursor would request the first page again
+		if info == nil || !info.HasNextPage || info.EndCursor == "" {
+			return nil
+		}
+
+		endCursor := info.EndCursor
+		cursor = &endCursor
+	}
+}
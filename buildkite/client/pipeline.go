@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"github.com/machinebox/graphql"
 	"github.com/pkg/errors"
@@ -21,6 +22,7 @@ type Pipeline struct {
 	Name                string                 `json:"name,omitempty"`
 	Description         string                 `json:"description"`
 	BranchConfiguration string                 `json:"branch_configuration"`
+	ClusterId           string                 `json:"cluster_id,omitempty"`
 	Provider            BuildkiteProvider      `json:"provider,omitempty"`
 	ProviderSettings    map[string]interface{} `json:"provider_settings,omitempty"`
 	// Buildkite doesn't allow you to create a pipeline if you not an admin or if you a member of more that one team or
@@ -29,9 +31,30 @@ type Pipeline struct {
 	TeamIDs []string `json:"team_ids,omitempty"`
 	Steps   []Step   `json:"steps,omitempty"`
 
+	// Teams are the teams with access to the pipeline and their access levels
+	// This value can only be set via the GraphQL API
+	Teams []PipelineTeam `json:"-"`
+
 	// Configuration is the "new" YAML based pipeline setup
 	// This value can only be set via the GraphQL API
 	Configuration string `json:"configuration,omitempty"`
+
+	// PipelineTemplateId is the GraphQL id of the template the steps of the pipeline come from, if any
+	// This value can only be set via the GraphQL API
+	PipelineTemplateId string `json:"-"`
+	// DetachPipelineTemplate removes the template of the pipeline when it is updated without a new template or YAML
+	// steps, which detach it anyway
+	DetachPipelineTemplate bool `json:"-"`
+	// ClearConfiguration removes the YAML steps of the pipeline when it is updated without YAML steps
+	ClearConfiguration bool `json:"-"`
+}
+
+// PipelineTeam is the access of a team to a pipeline
+type PipelineTeam struct {
+	TeamId      string
+	AccessLevel string
+	// TeamPipelineId is the GraphQL id of the access, it is only known for teams read from Buildkite
+	TeamPipelineId string
 }
 
 type BuildkiteProvider struct {
@@ -54,13 +77,13 @@ type Step struct {
 }
 
 type pipelineIdResponse struct {
-	Pipeline Node `json:"pipeline"`
+	Pipeline *Node `json:"pipeline"`
 }
 
-func (c *Client) GetPipeline(slug string) (*Pipeline, error) {
+func (c *Client) GetPipeline(ctx context.Context, slug string) (*Pipeline, error) {
 	pipeline := Pipeline{}
 	relativePath := fmt.Sprintf("/v2/organizations/%s/pipelines/%s", c.orgSlug, slug)
-	err := c.get(relativePath, &pipeline)
+	err := c.get(ctx, relativePath, &pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -72,23 +95,27 @@ func (c *Client) GetPipeline(slug string) (*Pipeline, error) {
 		pipeline.Environment = nil
 	}
 
-	pipeline.TeamIDs, err = c.getTeamIDs(slug)
+	pipeline.Teams, pipeline.PipelineTemplateId, err = c.getPipelineTeams(ctx, slug)
 	if err != nil {
 		return nil, err
 	}
+	pipeline.TeamIDs = make([]string, len(pipeline.Teams))
+	for i, team := range pipeline.Teams {
+		pipeline.TeamIDs[i] = team.TeamId
+	}
 
 	return &pipeline, nil
 }
 
-func (c *Client) CreatePipeline(pipeline *Pipeline) (*Pipeline, error) {
-	// Create via the GraphQL API if the YAML based configuration is used
-	if len(pipeline.Configuration) > 0 {
-		return c.createPipelineGraphQl(pipeline)
+func (c *Client) CreatePipeline(ctx context.Context, pipeline *Pipeline) (*Pipeline, error) {
+	// Create via the GraphQL API if the YAML based configuration or a template is used
+	if len(pipeline.Configuration) > 0 || len(pipeline.PipelineTemplateId) > 0 {
+		return c.createPipelineGraphQl(ctx, pipeline)
 	}
 
 	result := Pipeline{}
 	relativePath := fmt.Sprintf("/v2/organizations/%s/pipelines", c.orgSlug)
-	err := c.post(relativePath, pipeline, &result)
+	err := c.post(ctx, relativePath, pipeline, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -99,17 +126,18 @@ func (c *Client) CreatePipeline(pipeline *Pipeline) (*Pipeline, error) {
 // createPipelineGraphQl will create the pipeline but only set the required fields
 // after creation, UpdatePipeline will be used to set the rest of the fields via
 // the REST API
-func (c *Client) createPipelineGraphQl(pipeline *Pipeline) (*Pipeline, error) {
+func (c *Client) createPipelineGraphQl(ctx context.Context, pipeline *Pipeline) (*Pipeline, error) {
 	req := graphql.NewRequest(`
 mutation PipelineCreateRequest($pipelineCreateInput: PipelineCreateInput!) {
   pipelineCreate(input: $pipelineCreateInput) {
     pipeline {
+      id
       slug
     }
   }
 }`)
 
-	orgID, err := c.GetOrganizationId(c.orgSlug)
+	orgID, err := c.GetOrganizationId(ctx, c.orgSlug)
 	if err != nil {
 		return nil, err
 	}
@@ -120,19 +148,22 @@ mutation PipelineCreateRequest($pipelineCreateInput: PipelineCreateInput!) {
 		"repository": map[string]string{
 			"url": pipeline.Repository,
 		},
-		"steps": map[string]string{
+	}
+	if len(pipeline.PipelineTemplateId) > 0 {
+		pci["pipelineTemplateId"] = pipeline.PipelineTemplateId
+	} else {
+		pci["steps"] = map[string]string{
 			"yaml": pipeline.Configuration,
-		},
+		}
 	}
 
-	if len(pipeline.TeamIDs) != 0 {
+	if teams := pipeline.teamAccess(); len(teams) != 0 {
 		var teamIDs []map[string]string
 		// Converting a slice of team UUIDs into the slice of maps since GraphQL API expects this data in this shape.
-		// We grant "MANAGE_BUILD_AND_READ" access level to _initial_ teams-owners.
-		for _, ID := range pipeline.TeamIDs {
+		for _, team := range teams {
 			teamIDs = append(teamIDs, map[string]string{
-				"id":          ID,
-				"accessLevel": TeamPipelineAccessManage,
+				"id":          team.TeamId,
+				"accessLevel": team.AccessLevel,
 			})
 		}
 		pci["teams"] = teamIDs
@@ -143,42 +174,73 @@ mutation PipelineCreateRequest($pipelineCreateInput: PipelineCreateInput!) {
 	var createPipelineResponse struct {
 		PipelineCreate struct {
 			Pipeline struct {
+				ID   string `json:"id"`
 				Slug string `json:"slug"`
 			} `json:"pipeline"`
 		} `json:"pipelineCreate"`
 	}
 
-	if err := c.graphQLRequest(req, &createPipelineResponse); err != nil {
+	if err := c.graphQLRequest(ctx, req, &createPipelineResponse); err != nil {
 		return nil, errors.Wrapf(err, "failed to create pipeline %s", pipeline.Slug)
 	}
 
 	pipeline.Slug = createPipelineResponse.PipelineCreate.Pipeline.Slug
+	c.nodeIDs.set(pipelineKey(c.orgSlug, pipeline.Slug), createPipelineResponse.PipelineCreate.Pipeline.ID)
 
 	// set all other options with the rest api
-	return c.UpdatePipeline(pipeline)
+	return c.UpdatePipeline(ctx, pipeline)
 }
 
-func (c *Client) UpdatePipeline(pipeline *Pipeline) (*Pipeline, error) {
+func (c *Client) UpdatePipeline(ctx context.Context, pipeline *Pipeline) (*Pipeline, error) {
+	// A pipeline keeps the steps of its template when it is detached, so it is detached before any legacy steps are
+	// saved to replace them
+	if pipeline.DetachPipelineTemplate && len(pipeline.Configuration) == 0 {
+		if err := c.savePipelineSteps(ctx, pipeline); err != nil {
+			return nil, err
+		}
+	}
+
 	// Save other parameters via the REST API
-	result := Pipeline{TeamIDs: pipeline.TeamIDs} // Save TeamIDs as long as REST API doesn't provide them in response
+	// Save the teams and the template as long as REST API doesn't provide them in response
+	result := Pipeline{TeamIDs: pipeline.TeamIDs, Teams: pipeline.Teams, PipelineTemplateId: pipeline.PipelineTemplateId}
 	relativePath := fmt.Sprintf("/v2/organizations/%s/pipelines/%s", c.orgSlug, pipeline.Slug)
-	err := c.patch(relativePath, pipeline, &result)
+	var body interface{} = pipeline
+	if pipeline.ClearConfiguration && len(pipeline.Configuration) == 0 {
+		// the empty configuration is left out of the pipeline otherwise
+		body = struct {
+			*Pipeline
+			Configuration string `json:"configuration"`
+		}{Pipeline: pipeline}
+	}
+	err := c.patch(ctx, relativePath, body, &result)
 	if err != nil {
 		return nil, err
 	}
+	if result.Slug != pipeline.Slug {
+		// renaming a pipeline changes its slug, which is free to be taken by another pipeline now
+		c.nodeIDs.forget(pipelineKey(c.orgSlug, pipeline.Slug))
+	}
 
-	// Set YAML steps via the GraphQL API
-	if len(pipeline.Configuration) > 0 {
-		err := c.savePipelineYaml(pipeline)
+	// Set YAML steps or the template via the GraphQL API
+	if len(pipeline.Configuration) > 0 || len(pipeline.PipelineTemplateId) > 0 {
+		err := c.savePipelineSteps(ctx, pipeline)
 		if err != nil {
 			return nil, err
 		}
+		// the REST API returned the steps from before they were saved, like GetPipeline ignore any legacy steps
+		if len(pipeline.PipelineTemplateId) == 0 {
+			result.Configuration = pipeline.Configuration
+		}
+		result.Steps = nil
+		result.Environment = nil
 	}
 
 	return &result, nil
 }
 
-func (c *Client) savePipelineYaml(pipeline *Pipeline) error {
+// savePipelineSteps sets the YAML steps of the pipeline, or the template they come from. Saving YAML steps detaches
+// the pipeline from its template, without YAML steps the pipeline is only detached.
+func (c *Client) savePipelineSteps(ctx context.Context, pipeline *Pipeline) error {
 	req := graphql.NewRequest(`
 mutation PipelineUpdateMutation($pipelineUpdateInput: PipelineUpdateInput!) {
   pipelineUpdate(input: $pipelineUpdateInput) {
@@ -190,33 +252,117 @@ mutation PipelineUpdateMutation($pipelineUpdateInput: PipelineUpdateInput!) {
   }
 }`)
 
-	nodeID, err := c.GetPipelineNodeId(pipeline.Slug)
+	nodeID, err := c.GetPipelineNodeId(ctx, pipeline.Slug)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get GraphQL node id for %s", pipeline.Slug)
 	}
 
-	req.Var("pipelineUpdateInput", map[string]interface{}{
+	input := map[string]interface{}{
 		"id": nodeID,
-		"steps": map[string]interface{}{
+	}
+	if len(pipeline.PipelineTemplateId) > 0 {
+		input["pipelineTemplateId"] = pipeline.PipelineTemplateId
+	} else {
+		input["pipelineTemplateId"] = nil
+	}
+	if len(pipeline.PipelineTemplateId) == 0 && len(pipeline.Configuration) > 0 {
+		input["steps"] = map[string]interface{}{
 			"yaml": pipeline.Configuration,
-		},
-	})
+		}
+	}
+	req.Var("pipelineUpdateInput", input)
 
 	var gres interface{}
-	if err := c.graphQLRequest(req, &gres); err != nil {
+	if err := c.graphQLRequest(ctx, req, &gres); err != nil {
 		return errors.Wrapf(err, "failed to update pipeline %s", pipeline.Slug)
 	}
 
 	return nil
 }
 
-func (c *Client) getTeamIDs(slug string) ([]string, error) {
-	req := graphql.NewRequest(`
-query Pipeline($slug: ID!) {
+// teamAccess returns the teams of the pipeline, teams only given by TeamIDs get "MANAGE_BUILD_AND_READ" access
+func (pipeline *Pipeline) teamAccess() []PipelineTeam {
+	if len(pipeline.Teams) != 0 {
+		return pipeline.Teams
+	}
+
+	teams := make([]PipelineTeam, len(pipeline.TeamIDs))
+	for i, id := range pipeline.TeamIDs {
+		teams[i] = PipelineTeam{TeamId: id, AccessLevel: TeamPipelineAccessManage}
+	}
+	return teams
+}
+
+// UpdatePipelineTeams gives the teams access to the pipeline with their access levels and removes the access of the
+// removed teams, the access of any other team is left alone. Teams are added before others are removed, so that the
+// pipeline always belongs to a team.
+func (c *Client) UpdatePipelineTeams(ctx context.Context, slug string, teams []PipelineTeam, removedTeamIds []string) error {
+	log.Printf("[TRACE] Buildkite client UpdatePipelineTeams %s", slug)
+
+	current, _, err := c.getPipelineTeams(ctx, slug)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the teams of pipeline %s", slug)
+	}
+	currentByTeamId := map[string]PipelineTeam{}
+	for _, team := range current {
+		currentByTeamId[team.TeamId] = team
+	}
+
+	for _, team := range teams {
+		existing, ok := currentByTeamId[team.TeamId]
+		if !ok {
+			// all team pipelines are created as 'READ_ONLY', the access level is set by an update
+			created, err := c.CreateTeamPipeline(ctx, &TeamPipeline{
+				Team:     Node{Id: team.TeamId},
+				Pipeline: Node{Slug: slug},
+			})
+			if err != nil {
+				return err
+			}
+			existing = PipelineTeam{TeamId: team.TeamId, AccessLevel: created.AccessLevel, TeamPipelineId: created.Id}
+		}
+		if existing.AccessLevel == team.AccessLevel {
+			continue
+		}
+
+		_, err := c.UpdateTeamPipeline(ctx, &TeamPipeline{Id: existing.TeamPipelineId, AccessLevel: team.AccessLevel})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, teamId := range removedTeamIds {
+		team, ok := currentByTeamId[teamId]
+		if !ok {
+			// the access was already removed
+			continue
+		}
+		if err := c.DeleteTeamPipeline(ctx, team.TeamPipelineId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getPipelineTeams returns the teams with access to the pipeline and the id of its template, which is read with the
+// same query to save GetPipeline a request
+func (c *Client) getPipelineTeams(ctx context.Context, slug string) ([]PipelineTeam, string, error) {
+	query := `
+query Pipeline($slug: ID!, $first: Int!, $after: String) {
   pipeline(slug: $slug) {
-    teams(first: 100) {
+    pipelineTemplate {
+      id
+    }
+    teams(first: $first, after: $after) {
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
       edges {
         node {
+          id
+          accessLevel
           team {
             id
           }
@@ -224,45 +370,75 @@ query Pipeline($slug: ID!) {
       }
     }
   }
-}`)
+}`
+
+	teams := []PipelineTeam{}
+	templateId := ""
+	err := c.paginate(ctx, query, map[string]interface{}{"slug": c.createOrgSlug(slug)}, func(req *graphql.Request) (*pageInfo, error) {
+		var resp struct {
+			Pipeline *struct {
+				PipelineTemplate *Node `json:"pipelineTemplate"`
+				Teams            struct {
+					PageInfo pageInfo `json:"pageInfo"`
+					Edges    []struct {
+						Node struct {
+							ID          string `json:"id"`
+							AccessLevel string `json:"accessLevel"`
+							Team        struct {
+								ID string `json:"id"`
+							} `json:"team"`
+						} `json:"node"`
+					} `json:"edges"`
+				} `json:"teams"`
+			} `json:"pipeline"`
+		}
+		if err := c.graphQLRequest(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		if resp.Pipeline == nil {
+			return nil, &NotFound{}
+		}
+		if resp.Pipeline.PipelineTemplate != nil {
+			templateId = resp.Pipeline.PipelineTemplate.Id
+		}
 
-	req.Var("slug", c.createOrgSlug(slug))
-	var resp struct {
-		Pipeline struct {
-			Teams struct {
-				Edges []struct {
-					Node struct {
-						Team struct {
-							ID string `json:"id"`
-						} `json:"team"`
-					} `json:"node"`
-				} `json:"edges"`
-			} `json:"teams"`
-		} `json:"pipeline"`
-	}
-	if err := c.graphQLRequest(req, &resp); err != nil {
-		return nil, err
+		for _, edge := range resp.Pipeline.Teams.Edges {
+			teams = append(teams, PipelineTeam{
+				TeamId:         edge.Node.Team.ID,
+				AccessLevel:    edge.Node.AccessLevel,
+				TeamPipelineId: edge.Node.ID,
+			})
+		}
+		return &resp.Pipeline.Teams.PageInfo, nil
+	})
+	if err != nil {
+		return nil, "", err
 	}
 
-	teamIDs := make([]string, len(resp.Pipeline.Teams.Edges))
-	for i, edge := range resp.Pipeline.Teams.Edges {
-		teamIDs[i] = edge.Node.Team.ID
-	}
-	log.Printf("[TRACE] got team ids: %v", teamIDs)
-	return teamIDs, nil
+	log.Printf("[TRACE] got pipeline teams: %v", teams)
+	return teams, templateId, nil
 }
 
-func (c *Client) DeletePipeline(slug string) error {
+func (c *Client) DeletePipeline(ctx context.Context, slug string) error {
 	relativePath := fmt.Sprintf("/v2/organizations/%s/pipelines/%s", c.orgSlug, slug)
-	err := c.delete(relativePath, nil)
+	err := c.delete(ctx, relativePath, nil)
 	if err != nil {
 		return err
 	}
+	c.nodeIDs.forget(pipelineKey(c.orgSlug, slug))
 
 	return nil
 }
 
-func (c *Client) GetPipelineNodeId(slug string) (string, error) {
+// GetPipelineNodeId returns the GraphQL id of the pipeline with the given slug, it is only looked up once per
+// client
+func (c *Client) GetPipelineNodeId(ctx context.Context, slug string) (string, error) {
+	return c.nodeIDs.lookup(ctx, pipelineKey(c.orgSlug, slug), func(ctx context.Context) (string, error) {
+		return c.fetchPipelineNodeId(ctx, slug)
+	})
+}
+
+func (c *Client) fetchPipelineNodeId(ctx context.Context, slug string) (string, error) {
 	req := graphql.NewRequest(`
 query GetPipelineId($pipelineSlug: ID!) {
   pipeline(slug: $pipelineSlug) {
@@ -272,9 +448,12 @@ query GetPipelineId($pipelineSlug: ID!) {
 	req.Var("pipelineSlug", c.createOrgSlug(slug))
 
 	idResponse := pipelineIdResponse{}
-	if err := c.graphQLRequest(req, &idResponse); err != nil {
+	if err := c.graphQLRequest(ctx, req, &idResponse); err != nil {
 		return "", err
 	}
+	if idResponse.Pipeline == nil {
+		return "", &NotFound{}
+	}
 
 	return idResponse.Pipeline.Id, nil
 }
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"log"
 	"strings"
 
@@ -9,7 +10,7 @@ import (
 )
 
 type pipelineScheduleResponse struct {
-	PipelineSchedule PipelineSchedule `json:"pipelineSchedule"`
+	PipelineSchedule *PipelineSchedule `json:"pipelineSchedule"`
 }
 
 type PipelineSchedule struct {
@@ -44,7 +45,7 @@ type pipelineScheduleDeleteResponse struct {
 	DeletedPipelineScheduleID string `json:"deletedPipelineScheduleID"`
 }
 
-func (c *Client) GetPipelineSchedule(pipelineScheduleSlug string) (*PipelineSchedule, error) {
+func (c *Client) GetPipelineSchedule(ctx context.Context, pipelineScheduleSlug string) (*PipelineSchedule, error) {
 	log.Printf("[TRACE] Buildkite client GetPipelineSchedule %s", pipelineScheduleSlug)
 
 	req := graphql.NewRequest(`
@@ -70,17 +71,20 @@ query GetPipelineSchedule($pipelineScheduleSlug: ID!) {
 	req.Var("pipelineScheduleSlug", c.createOrgSlug(pipelineScheduleSlug))
 
 	response := pipelineScheduleResponse{}
-	if err := c.graphQLRequest(req, &response); err != nil {
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
 		return nil, errors.Wrapf(err, "failed to get pipeline schedule %s", pipelineScheduleSlug)
 	}
+	if response.PipelineSchedule == nil {
+		return nil, &NotFound{}
+	}
 
-	return &response.PipelineSchedule, nil
+	return response.PipelineSchedule, nil
 }
 
-func (c *Client) CreatePipelineSchedule(pipelineSchedule *PipelineSchedule) (*PipelineSchedule, error) {
+func (c *Client) CreatePipelineSchedule(ctx context.Context, pipelineSchedule *PipelineSchedule) (*PipelineSchedule, error) {
 	log.Printf("[TRACE] Buildkite client CreatePipelineSchedule %s", pipelineSchedule.UUID)
 
-	pipelineId, err := c.GetPipelineNodeId(pipelineSchedule.Pipeline.Slug)
+	pipelineId, err := c.GetPipelineNodeId(ctx, pipelineSchedule.Pipeline.Slug)
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not get schedule id for slug %s", pipelineSchedule.Pipeline.Slug)
 	}
@@ -122,14 +126,14 @@ mutation PipelineScheduleNewMutation($pipelineScheduleCreateInput: PipelineSched
 	})
 
 	response := pipelineScheduleCreateResponse{}
-	if err := c.graphQLRequest(req, &response); err != nil {
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
 		return nil, errors.Wrapf(err, "failed to create pipeline schedule for pipeline %s", pipelineSchedule.Pipeline.Slug)
 	}
 
 	return &response.PipelineScheduleCreate.PipelineScheduleEdge.Node, nil
 }
 
-func (c *Client) UpdatePipelineSchedule(pipelineSchedule *PipelineSchedule) (*PipelineSchedule, error) {
+func (c *Client) UpdatePipelineSchedule(ctx context.Context, pipelineSchedule *PipelineSchedule) (*PipelineSchedule, error) {
 
 	req := graphql.NewRequest(`
 mutation PipelineScheduleUpdateMutation($pipelineScheduleUpdateInput: PipelineScheduleUpdateInput!) {
@@ -166,14 +170,14 @@ mutation PipelineScheduleUpdateMutation($pipelineScheduleUpdateInput: PipelineSc
 	})
 
 	response := pipelineScheduleUpdateResponse{}
-	if err := c.graphQLRequest(req, &response); err != nil {
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
 		return nil, errors.Wrapf(err, "failed to update pipeline schedule %s", pipelineSchedule.Id)
 	}
 
 	return &response.PipelineScheduleUpdate.PipelineSchedule, nil
 }
 
-func (c *Client) DeletePipelineSchedule(pipelineScheduleId string) error {
+func (c *Client) DeletePipelineSchedule(ctx context.Context, pipelineScheduleId string) error {
 	req := graphql.NewRequest(`
 mutation PipelineScheduleDeleteMutation($pipelineScheduleDeleteInput: PipelineScheduleDeleteInput!) {
   pipelineScheduleDelete(input: $pipelineScheduleDeleteInput) {
@@ -187,7 +191,7 @@ mutation PipelineScheduleDeleteMutation($pipelineScheduleDeleteInput: PipelineSc
 	})
 
 	response := pipelineScheduleDeleteResponse{}
-	if err := c.graphQLRequest(req, &response); err != nil {
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
 		return errors.Wrapf(err, "failed to delete pipeline schedule %s", pipelineScheduleId)
 	}
 
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"github.com/machinebox/graphql"
+	"github.com/pkg/errors"
+	"log"
+)
+
+type pipelineTemplateResponse struct {
+	PipelineTemplate *PipelineTemplate `json:"pipelineTemplate"`
+}
+
+// PipelineTemplate is a YAML step configuration shared by pipelines, pipelines which use it can't change their
+// steps themselves
+type PipelineTemplate struct {
+	Id            string `json:"id,omitempty"`
+	UUID          string `json:"uuid,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Description   string `json:"description,omitempty"`
+	Configuration string `json:"configuration,omitempty"`
+	// Available templates can be picked by everyone creating a pipeline, others only by administrators
+	Available bool `json:"available"`
+}
+
+type pipelineTemplateCreateResponse struct {
+	PipelineTemplateCreate struct {
+		PipelineTemplate PipelineTemplate
+	}
+}
+
+type pipelineTemplateUpdateResponse struct {
+	PipelineTemplateUpdate struct {
+		PipelineTemplate PipelineTemplate
+	}
+}
+
+type pipelineTemplateDeleteResponse struct {
+	DeletedPipelineTemplateID string `json:"deletedPipelineTemplateID"`
+}
+
+func (c *Client) GetPipelineTemplate(ctx context.Context, id string) (*PipelineTemplate, error) {
+	log.Printf("[TRACE] Buildkite client GetPipelineTemplate %s", id)
+
+	req := graphql.NewRequest(`
+query GetPipelineTemplate($pipelineTemplateId: ID!) {
+  pipelineTemplate: node(id: $pipelineTemplateId) {
+    ... on PipelineTemplate {
+      id
+      uuid
+      name
+      description
+      configuration
+      available
+    }
+  }
+}
+`)
+	req.Var("pipelineTemplateId", id)
+
+	response := pipelineTemplateResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to get pipeline template %s", id)
+	}
+	// the node is null if it does not exist and empty if the id belongs to a different type
+	if response.PipelineTemplate == nil || response.PipelineTemplate.Id == "" {
+		return nil, &NotFound{}
+	}
+
+	return response.PipelineTemplate, nil
+}
+
+func (c *Client) CreatePipelineTemplate(ctx context.Context, template *PipelineTemplate) (*PipelineTemplate, error) {
+	log.Printf("[TRACE] Buildkite client CreatePipelineTemplate %s", template.Name)
+
+	orgId, err := c.GetOrganizationId(ctx, c.orgSlug)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch organization id")
+	}
+
+	req := graphql.NewRequest(`
+mutation PipelineTemplateCreateMutation($pipelineTemplateCreateInput: PipelineTemplateCreateInput!) {
+  pipelineTemplateCreate(input: $pipelineTemplateCreateInput) {
+    pipelineTemplate {
+      id
+      uuid
+      name
+      description
+      configuration
+      available
+    }
+  }
+}
+`)
+	req.Var("pipelineTemplateCreateInput", map[string]interface{}{
+		"organizationId": orgId,
+		"name":           template.Name,
+		"description":    template.Description,
+		"configuration":  template.Configuration,
+		"available":      template.Available,
+	})
+
+	response := pipelineTemplateCreateResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to create pipeline template %s", template.Name)
+	}
+
+	return &response.PipelineTemplateCreate.PipelineTemplate, nil
+}
+
+func (c *Client) UpdatePipelineTemplate(ctx context.Context, template *PipelineTemplate) (*PipelineTemplate, error) {
+	log.Printf("[TRACE] Buildkite client UpdatePipelineTemplate %s", template.Id)
+
+	orgId, err := c.GetOrganizationId(ctx, c.orgSlug)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch organization id")
+	}
+
+	req := graphql.NewRequest(`
+mutation PipelineTemplateUpdateMutation($pipelineTemplateUpdateInput: PipelineTemplateUpdateInput!) {
+  pipelineTemplateUpdate(input: $pipelineTemplateUpdateInput) {
+    pipelineTemplate {
+      id
+      uuid
+      name
+      description
+      configuration
+      available
+    }
+  }
+}
+`)
+	req.Var("pipelineTemplateUpdateInput", map[string]interface{}{
+		"organizationId": orgId,
+		"id":             template.Id,
+		"name":           template.Name,
+		"description":    template.Description,
+		"configuration":  template.Configuration,
+		"available":      template.Available,
+	})
+
+	response := pipelineTemplateUpdateResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to update pipeline template %s", template.Id)
+	}
+
+	return &response.PipelineTemplateUpdate.PipelineTemplate, nil
+}
+
+// DeletePipelineTemplate deletes the template, Buildkite refuses to delete a template pipelines still use
+func (c *Client) DeletePipelineTemplate(ctx context.Context, id string) error {
+	log.Printf("[TRACE] Buildkite client DeletePipelineTemplate %s", id)
+
+	orgId, err := c.GetOrganizationId(ctx, c.orgSlug)
+	if err != nil {
+		return errors.Wrap(err, "could not fetch organization id")
+	}
+
+	req := graphql.NewRequest(`
+mutation PipelineTemplateDeleteMutation($pipelineTemplateDeleteInput: PipelineTemplateDeleteInput!) {
+  pipelineTemplateDelete(input: $pipelineTemplateDeleteInput) {
+    deletedPipelineTemplateID
+  }
+}
+`)
+	req.Var("pipelineTemplateDeleteInput", map[string]interface{}{
+		"organizationId": orgId,
+		"id":             id,
+	})
+
+	response := pipelineTemplateDeleteResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return errors.Wrapf(err, "failed to delete pipeline template %s", id)
+	}
+
+	return nil
+}
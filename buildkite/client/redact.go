@@ -0,0 +1,211 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	redactedValue = "(sensitive value)"
+	// maxLogLength caps the size of a logged body, so that listing large connections doesn't flood the log
+	maxLogLength = 16 * 1024
+)
+
+// sensitiveFields are the fields of request and response bodies whose values are never logged, in addition to
+// the ones given in Options.SensitiveFields
+var sensitiveFields = []string{
+	"env",
+	"token",
+	"token_value",
+	"api_token",
+	"access_token",
+	"secret",
+	"password",
+	"authorization",
+	"webhook_url",
+	"provider_settings",
+}
+
+// yamlFields are the fields holding YAML documents, e.g. the steps of a pipeline, whose sensitive values are masked
+// the same way, e.g. the values of env mappings
+var yamlFields = []string{
+	"configuration",
+	"yaml",
+}
+
+// redactor masks sensitive values before request and response bodies are logged. Field names are compared
+// ignoring case and underscores, so that both the snake_case REST and camelCase GraphQL fields are matched.
+type redactor struct {
+	fields     map[string]bool
+	yamlFields map[string]bool
+}
+
+func newRedactor(extraFields []string) *redactor {
+	r := &redactor{fields: map[string]bool{}, yamlFields: map[string]bool{}}
+	for _, field := range append(sensitiveFields, extraFields...) {
+		r.fields[normalizeFieldName(field)] = true
+	}
+	for _, field := range yamlFields {
+		r.yamlFields[normalizeFieldName(field)] = true
+	}
+	return r
+}
+
+func normalizeFieldName(field string) string {
+	return strings.ToLower(strings.Replace(strings.Replace(field, "_", "", -1), "-", "", -1))
+}
+
+// body returns the JSON document with all sensitive values masked, truncated to maxLogLength
+func (r *redactor) body(body []byte) string {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		// not JSON, e.g. an error page of a proxy
+		return truncate(string(body))
+	}
+	return r.value(document)
+}
+
+// value returns the JSON encoding of v with all sensitive values masked, truncated to maxLogLength
+func (r *redactor) value(v interface{}) string {
+	// round trip through JSON, so that structs are redacted by their JSON field names
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("(unable to log value: %v)", err)
+	}
+	var document interface{}
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return fmt.Sprintf("(unable to log value: %v)", err)
+	}
+
+	redacted, _ := json.MarshalIndent(r.redact(document), "", "  ")
+	return truncate(string(redacted))
+}
+
+func (r *redactor) redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if r.fields[normalizeFieldName(key)] {
+				result[key] = mask(value)
+			} else if document, ok := value.(string); ok && r.yamlFields[normalizeFieldName(key)] {
+				result[key] = r.yaml(document)
+			} else {
+				result[key] = r.redact(value)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, value := range v {
+			result[i] = r.redact(value)
+		}
+		return result
+	}
+	return v
+}
+
+// mask replaces every value in v, keys of objects are kept since they help to make sense of the log, e.g. the
+// names of environment variables
+func mask(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			result[key] = mask(value)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, value := range v {
+			result[i] = mask(value)
+		}
+		return result
+	}
+	return redactedValue
+}
+
+// yaml returns the YAML document with all sensitive values masked, documents which can't be parsed are masked
+// entirely
+func (r *redactor) yaml(document string) string {
+	if strings.TrimSpace(document) == "" {
+		return document
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(document), &node); err != nil {
+		return redactedValue
+	}
+	r.redactYAML(&node)
+
+	var redacted bytes.Buffer
+	encoder := yaml.NewEncoder(&redacted)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return redactedValue
+	}
+	return redacted.String()
+}
+
+func (r *redactor) redactYAML(node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		for _, child := range node.Content {
+			r.redactYAML(child)
+		}
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if r.fields[normalizeFieldName(node.Content[i].Value)] {
+			maskYAML(node.Content[i+1])
+		} else {
+			r.redactYAML(node.Content[i+1])
+		}
+	}
+}
+
+// maskYAML replaces every value in node like mask does, keys of mappings are kept
+func maskYAML(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		node.Value = redactedValue
+		node.Tag = "!!str"
+		node.Style = 0
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			maskYAML(node.Content[i])
+		}
+	case yaml.AliasNode:
+		// the values are masked where they are anchored
+		maskYAML(node.Alias)
+	default:
+		for _, child := range node.Content {
+			maskYAML(child)
+		}
+	}
+}
+
+// header returns a copy of the header without credentials
+func (r *redactor) header(header http.Header) http.Header {
+	result := header.Clone()
+	for key := range result {
+		if r.fields[normalizeFieldName(key)] {
+			result.Set(key, redactedValue)
+		}
+	}
+	return result
+}
+
+func truncate(s string) string {
+	if len(s) <= maxLogLength {
+		return s
+	}
+	return fmt.Sprintf("%s... (%d more bytes)", s[:maxLogLength], len(s)-maxLogLength)
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRedactorBody(t *testing.T) {
+	r := newRedactor([]string{"custom_secret"})
+
+	logged := r.body([]byte(`{
+		"name": "deploy",
+		"env": {"AWS_SECRET_ACCESS_KEY": "hunter2"},
+		"provider": {"webhook_url": "https://webhook.buildkite.com/deliver/abc"},
+		"input": {"customSecret": "s3cret", "teams": [{"token": "t0ken"}]}
+	}`))
+
+	for _, secret := range []string{"hunter2", "webhook.buildkite.com", "s3cret", "t0ken"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("expected %q to be redacted from %s", secret, logged)
+		}
+	}
+	for _, kept := range []string{"deploy", "AWS_SECRET_ACCESS_KEY"} {
+		if !strings.Contains(logged, kept) {
+			t.Errorf("expected %q to be logged in %s", kept, logged)
+		}
+	}
+}
+
+func TestRedactorBodyProviderSettings(t *testing.T) {
+	logged := newRedactor(nil).body([]byte(`{
+		"provider_settings": {"trigger_mode": "code", "filter_condition": "build.env(\"DEPLOY_KEY\") == \"hunter2\""},
+		"variables": {"input": {"providerSettings": {"webhookSecret": "s3cret"}}}
+	}`))
+
+	for _, secret := range []string{"hunter2", "s3cret"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("expected %q to be redacted from %s", secret, logged)
+		}
+	}
+	if !strings.Contains(logged, "filter_condition") {
+		t.Errorf("expected the names of the settings to be logged in %s", logged)
+	}
+}
+
+func TestRedactorBodyConfiguration(t *testing.T) {
+	r := newRedactor(nil)
+	configuration := "env:\n  AWS_SECRET_ACCESS_KEY: hunter2\nsteps:\n  - command: make deploy\n    env:\n      DEPLOY_TOKEN: s3cret\n" +
+		"  - command: make test\n    env: *shared\n"
+	configuration = "x-env: &shared\n  NPM_TOKEN: t0ken\n" + configuration
+
+	for _, body := range []string{
+		// REST
+		`{"configuration": ` + strconv.Quote(configuration) + `}`,
+		// GraphQL
+		`{"variables": {"pipelineUpdateInput": {"steps": {"yaml": ` + strconv.Quote(configuration) + `}}}}`,
+	} {
+		logged := r.body([]byte(body))
+		for _, secret := range []string{"hunter2", "s3cret", "t0ken"} {
+			if strings.Contains(logged, secret) {
+				t.Errorf("expected %q to be redacted from %s", secret, logged)
+			}
+		}
+		for _, kept := range []string{"make deploy", "AWS_SECRET_ACCESS_KEY", "DEPLOY_TOKEN"} {
+			if !strings.Contains(logged, kept) {
+				t.Errorf("expected %q to be logged in %s", kept, logged)
+			}
+		}
+	}
+
+	logged := r.body([]byte(`{"configuration": "steps: [\n  - command: echo hunter2"}`))
+	if strings.Contains(logged, "hunter2") {
+		t.Errorf("expected configuration which isn't YAML to be redacted from %s", logged)
+	}
+}
+
+func TestRedactorBodyTruncates(t *testing.T) {
+	logged := newRedactor(nil).body([]byte(strings.Repeat("x", maxLogLength+10)))
+
+	if !strings.HasSuffix(logged, "... (10 more bytes)") {
+		t.Errorf("expected the body to be truncated, got ...%s", logged[len(logged)-30:])
+	}
+}
+
+func TestRedactorHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer abc")
+	header.Set("Content-Type", applicationJsonContentType)
+
+	redacted := newRedactor(nil).header(header)
+
+	if redacted.Get("Authorization") != redactedValue {
+		t.Errorf("expected the Authorization header to be redacted, got %q", redacted.Get("Authorization"))
+	}
+	if redacted.Get("Content-Type") != applicationJsonContentType {
+		t.Errorf("expected the Content-Type header to be kept, got %q", redacted.Get("Content-Type"))
+	}
+	if header.Get("Authorization") != "Bearer abc" {
+		t.Errorf("expected the original header to be left untouched")
+	}
+}
@@ -1,11 +1,12 @@
 package client
 
 import (
-	"fmt"
+	"context"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 type NotFound struct {
@@ -15,26 +16,26 @@ func (err *NotFound) Error() string {
 	return "404 Not Found"
 }
 
-func (c *Client) get(relativePath string, responseBody interface{}) error {
-	return c.request("GET", relativePath, nil, responseBody)
+func (c *Client) get(ctx context.Context, relativePath string, responseBody interface{}) error {
+	return c.request(ctx, "GET", relativePath, nil, responseBody)
 }
 
-func (c *Client) post(relativePath string, requestBody interface{}, responseBody interface{}) error {
-	return c.request("POST", relativePath, requestBody, responseBody)
+func (c *Client) post(ctx context.Context, relativePath string, requestBody interface{}, responseBody interface{}) error {
+	return c.request(ctx, "POST", relativePath, requestBody, responseBody)
 }
 
-func (c *Client) patch(relativePath string, requestBody interface{}, responseBody interface{}) error {
-	return c.request("PATCH", relativePath, requestBody, responseBody)
+func (c *Client) patch(ctx context.Context, relativePath string, requestBody interface{}, responseBody interface{}) error {
+	return c.request(ctx, "PATCH", relativePath, requestBody, responseBody)
 }
 
-func (c *Client) delete(relativePath string, responseBody interface{}) error {
-	return c.request("DELETE", relativePath, nil, responseBody)
+func (c *Client) delete(ctx context.Context, relativePath string, responseBody interface{}) error {
+	return c.request(ctx, "DELETE", relativePath, nil, responseBody)
 }
 
-func (c *Client) request(method string, relativePath string, requestBody interface{}, responseBody interface{}) error {
+func (c *Client) request(ctx context.Context, method string, relativePath string, requestBody interface{}, responseBody interface{}) error {
 	log.Printf("[DEBUG] Buildkite Request %s %s\n", method, relativePath)
 
-	req, err := createRequest(method, c.urlPath(relativePath), requestBody)
+	req, err := c.createRequest(ctx, method, c.urlPath(relativePath), requestBody)
 	if err != nil {
 		return err
 	}
@@ -51,11 +52,11 @@ func (c *Client) request(method string, relativePath string, requestBody interfa
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("%s\nResponse body:\n\n%s\n", resp.Status, body)
+		return newRESTError(resp, body)
 	}
 
 	if responseBody != nil {
-		if err = unmarshalResponse(resp.Body, &responseBody); err != nil {
+		if err = c.unmarshalResponse(resp.Body, &responseBody); err != nil {
 			return err
 		}
 	}
@@ -63,21 +64,22 @@ func (c *Client) request(method string, relativePath string, requestBody interfa
 }
 
 func (c *Client) urlPath(relativePath string) string {
+	// resolve the path relative to the base URL, so that a path prefix of the base URL is kept
 	return c.baseURL.ResolveReference(&url.URL{
-		Path: relativePath,
+		Path: strings.TrimPrefix(relativePath, "/"),
 	}).String()
 }
 
-func createRequest(method string, url string, requestBody interface{}) (*http.Request, error) {
+func (c *Client) createRequest(ctx context.Context, method string, url string, requestBody interface{}) (*http.Request, error) {
 	if requestBody == nil {
-		return http.NewRequest(method, url, nil)
+		return http.NewRequestWithContext(ctx, method, url, nil)
 	}
 
-	body, err := marshalBody(requestBody)
+	body, err := c.marshalBody(requestBody)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest(method, url, body)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultMaxRetries = 5
+
+	retryWaitMin = 500 * time.Millisecond
+	retryWaitMax = 30 * time.Second
+
+	rateLimitRemainingHeader = "RateLimit-Remaining"
+	rateLimitResetHeader     = "RateLimit-Reset"
+	retryAfterHeader         = "Retry-After"
+)
+
+// retryTransport retries requests which failed with a transient error using jittered exponential backoff.
+// Requests which may have been applied already are only retried when Buildkite turned them away before handling
+// them (429 or 503), idempotent requests are also retried on 502, 504 and a reset connection. It also keeps track
+// of Buildkite's RateLimit-* headers and holds back requests until the rate limit window resets once the remaining
+// budget is exhausted.
+type retryTransport struct {
+	transport  http.RoundTripper
+	maxRetries int
+	// sleep waits between attempts, tests replace it to not wait for real
+	sleep func(ctx context.Context, d time.Duration) error
+
+	mutex      sync.Mutex
+	pauseUntil time.Time
+}
+
+func newRetryTransport(transport http.RoundTripper, maxRetries int) *retryTransport {
+	return &retryTransport{
+		transport:  transport,
+		maxRetries: maxRetries,
+		sleep:      sleep,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := t.sleep(req.Context(), t.rateLimitPause()); err != nil {
+			return nil, err
+		}
+
+		attemptReq, err := cloneRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.transport.RoundTrip(attemptReq)
+		if resp != nil {
+			t.observeRateLimit(resp)
+		}
+
+		if attempt >= t.maxRetries || !isRetryable(req.Method, resp, err) {
+			return resp, err
+		}
+
+		wait := retryDelay(attempt, resp)
+		if resp != nil {
+			log.Printf("[DEBUG] Buildkite %s %s returned %s, retrying in %s (%d/%d)",
+				req.Method, req.URL.Path, resp.Status, wait, attempt+1, t.maxRetries)
+			// drain the body so that the connection can be reused
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			log.Printf("[DEBUG] Buildkite %s %s failed with %v, retrying in %s (%d/%d)",
+				req.Method, req.URL.Path, err, wait, attempt+1, t.maxRetries)
+		}
+
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// rateLimitPause returns how long to wait before sending the next request
+func (t *retryTransport) rateLimitPause() time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return time.Until(t.pauseUntil)
+}
+
+// observeRateLimit pauses all further requests until the rate limit resets once no requests are remaining
+func (t *retryTransport) observeRateLimit(resp *http.Response) {
+	if resp.Header.Get(rateLimitRemainingHeader) != "0" {
+		return
+	}
+
+	reset, ok := parseSeconds(resp.Header.Get(rateLimitResetHeader))
+	if !ok {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if until := time.Now().Add(reset); until.After(t.pauseUntil) {
+		log.Printf("[DEBUG] Buildkite rate limit exhausted, pausing requests for %s", reset)
+		t.pauseUntil = until
+	}
+}
+
+// cloneRequest returns a copy of the request with a fresh body, so that it can be sent more than once
+func cloneRequest(req *http.Request, attempt int) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return clone, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, errors.Errorf("unable to retry %s %s: request body cannot be rewound", req.Method, req.URL.Path)
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not rewind request body")
+	}
+	clone.Body = body
+	return clone, nil
+}
+
+// isRetryable reports whether a request can safely be sent again. A POST or PATCH which failed with a 502, 504 or a
+// reset connection may have been applied already, sending it again could e.g. create a second pipeline.
+func isRetryable(method string, resp *http.Response, err error) bool {
+	if !isIdempotent(method) {
+		return err == nil &&
+			(resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable)
+	}
+
+	if err != nil {
+		return isConnectionReset(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isConnectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) ||
+		strings.Contains(err.Error(), "connection reset by peer")
+}
+
+// retryDelay honours the delay requested by the server, otherwise it backs off exponentially
+func retryDelay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		for _, header := range []string{rateLimitResetHeader, retryAfterHeader} {
+			if wait, ok := parseSeconds(resp.Header.Get(header)); ok {
+				// spread out clients which were all told to come back at the same time
+				return wait + time.Duration(rand.Int63n(int64(time.Second)))
+			}
+		}
+	}
+
+	return backoff(attempt)
+}
+
+// backoff returns an exponentially growing delay with jitter, capped at retryWaitMax
+func backoff(attempt int) time.Duration {
+	wait := retryWaitMax
+	if attempt < 16 {
+		if exp := retryWaitMin << uint(attempt); exp < retryWaitMax {
+			wait = exp
+		}
+	}
+
+	half := int64(wait / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+func parseSeconds(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestRetryTransport returns a transport which records the waits instead of sleeping
+func newTestRetryTransport(maxRetries int) (*retryTransport, *[]time.Duration) {
+	var waits []time.Duration
+	transport := newRetryTransport(http.DefaultTransport, maxRetries)
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		if d > 0 {
+			waits = append(waits, d)
+		}
+		return nil
+	}
+	return transport, &waits
+}
+
+func TestRetryTransportAttempts(t *testing.T) {
+	cases := []struct {
+		method   string
+		status   int
+		attempts int32
+	}{
+		{http.MethodGet, http.StatusOK, 1},
+		{http.MethodGet, http.StatusNotFound, 1},
+		{http.MethodGet, http.StatusTooManyRequests, 4},
+		{http.MethodGet, http.StatusBadGateway, 4},
+		{http.MethodGet, http.StatusServiceUnavailable, 4},
+		{http.MethodGet, http.StatusGatewayTimeout, 4},
+		{http.MethodHead, http.StatusBadGateway, 4},
+		{http.MethodPut, http.StatusGatewayTimeout, 4},
+		{http.MethodDelete, http.StatusBadGateway, 4},
+		{http.MethodPost, http.StatusTooManyRequests, 4},
+		{http.MethodPost, http.StatusServiceUnavailable, 4},
+		{http.MethodPost, http.StatusBadGateway, 1},
+		{http.MethodPost, http.StatusGatewayTimeout, 1},
+		{http.MethodPost, http.StatusInternalServerError, 1},
+		{http.MethodPatch, http.StatusTooManyRequests, 4},
+		{http.MethodPatch, http.StatusBadGateway, 1},
+	}
+
+	for _, c := range cases {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(c.status)
+		}))
+
+		transport, _ := newTestRetryTransport(3)
+		req, _ := http.NewRequest(c.method, server.URL, strings.NewReader("{}"))
+		resp, err := transport.RoundTrip(req)
+		server.Close()
+
+		if err != nil {
+			t.Errorf("%s %d: %s", c.method, c.status, err)
+			continue
+		}
+		if resp.StatusCode != c.status {
+			t.Errorf("%s %d: expected the last response to be returned, got %d", c.method, c.status, resp.StatusCode)
+		}
+		if attempts != c.attempts {
+			t.Errorf("%s %d: expected %d attempts, got %d", c.method, c.status, c.attempts, attempts)
+		}
+	}
+}
+
+func TestRetryTransportConnectionReset(t *testing.T) {
+	cases := []struct {
+		method   string
+		attempts int32
+	}{
+		{http.MethodGet, 3},
+		{http.MethodDelete, 3},
+		{http.MethodPost, 1},
+		{http.MethodPatch, 1},
+	}
+
+	for _, c := range cases {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			// close the connection without a response
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+		}))
+
+		transport, _ := newTestRetryTransport(2)
+		transport.transport = &http.Transport{DisableKeepAlives: true}
+		req, _ := http.NewRequest(c.method, server.URL, strings.NewReader("{}"))
+		_, err := transport.RoundTrip(req)
+		server.Close()
+
+		if err == nil {
+			t.Errorf("%s: expected the connection error to be returned", c.method)
+		}
+		if attempts != c.attempts {
+			t.Errorf("%s: expected %d attempts, got %d", c.method, c.attempts, attempts)
+		}
+	}
+}
+
+func TestRetryTransportRewindsBody(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"name":"test"}` {
+			t.Errorf("attempt %d: expected the whole body, got %q", attempts, body)
+		}
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	transport, _ := newTestRetryTransport(3)
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"name":"test"}`))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || attempts != 2 {
+		t.Errorf("expected to succeed on the second attempt, got %d after %d attempts", resp.StatusCode, attempts)
+	}
+}
+
+func TestRetryTransportWaits(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&attempts, 1) {
+		case 1:
+			w.Header().Set(retryAfterHeader, "3")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 3:
+			// the last request of the rate limit window
+			w.Header().Set(rateLimitRemainingHeader, "0")
+			w.Header().Set(rateLimitResetHeader, "10")
+		}
+	}))
+	defer server.Close()
+
+	transport, waits := newTestRetryTransport(3)
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		if _, err := transport.RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(*waits) != 3 {
+		t.Fatalf("expected 3 waits, got %v", *waits)
+	}
+	if wait := (*waits)[0]; wait < 3*time.Second || wait > 4*time.Second {
+		t.Errorf("expected to wait for Retry-After, got %s", wait)
+	}
+	if wait := (*waits)[1]; wait < retryWaitMin/2 || wait > retryWaitMin*2 {
+		t.Errorf("expected to back off after the second attempt, got %s", wait)
+	}
+	if wait := (*waits)[2]; wait < 9*time.Second || wait > 10*time.Second {
+		t.Errorf("expected the next request to wait for the rate limit to reset, got %s", wait)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 40; attempt++ {
+		ceiling := retryWaitMax
+		if attempt < 16 && retryWaitMin<<uint(attempt) < retryWaitMax {
+			ceiling = retryWaitMin << uint(attempt)
+		}
+
+		for i := 0; i < 20; i++ {
+			wait := backoff(attempt)
+			if wait < ceiling/2 || wait > ceiling {
+				t.Fatalf("attempt %d: expected a wait between %s and %s, got %s", attempt, ceiling/2, ceiling, wait)
+			}
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	cases := []struct {
+		name     string
+		header   map[string]string
+		min, max time.Duration
+	}{
+		{"retry after", map[string]string{retryAfterHeader: "5"}, 5 * time.Second, 6 * time.Second},
+		{"rate limit reset", map[string]string{rateLimitResetHeader: "7", retryAfterHeader: "1"}, 7 * time.Second, 8 * time.Second},
+		{"zero", map[string]string{retryAfterHeader: "0"}, 0, time.Second},
+		{"http date", map[string]string{retryAfterHeader: "Wed, 21 Oct 2015 07:28:00 GMT"}, retryWaitMin / 2, retryWaitMin},
+		{"negative", map[string]string{retryAfterHeader: "-3"}, retryWaitMin / 2, retryWaitMin},
+		{"missing", nil, retryWaitMin / 2, retryWaitMin},
+	}
+
+	for _, c := range cases {
+		resp := &http.Response{Header: http.Header{}}
+		for key, value := range c.header {
+			resp.Header.Set(key, value)
+		}
+
+		if wait := retryDelay(0, resp); wait < c.min || wait > c.max {
+			t.Errorf("%s: expected a wait between %s and %s, got %s", c.name, c.min, c.max, wait)
+		}
+	}
+}
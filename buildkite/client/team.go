@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"github.com/machinebox/graphql"
 	"github.com/pkg/errors"
 )
@@ -14,7 +15,7 @@ const (
 )
 
 type teamResponse struct {
-	Team Team `json:"team"`
+	Team *Team `json:"team"`
 }
 
 type Team struct {
@@ -47,7 +48,7 @@ type teamDeleteResponse struct {
 	DeletedTeamID string `json:"deletedTeamID"`
 }
 
-func (c *Client) GetTeam(slug string) (*Team, error) {
+func (c *Client) GetTeam(ctx context.Context, slug string) (*Team, error) {
 	req := graphql.NewRequest(`
 query GetTeam($teamSlug: ID!) {
   team(slug: $teamSlug) {
@@ -65,16 +66,19 @@ query GetTeam($teamSlug: ID!) {
 	req.Var("teamSlug", c.createOrgSlug(slug))
 
 	teamResponse := teamResponse{}
-	if err := c.graphQLRequest(req, &teamResponse); err != nil {
+	if err := c.graphQLRequest(ctx, req, &teamResponse); err != nil {
 		return nil, errors.Wrapf(err, "failed to get team %s", slug)
 	}
+	if teamResponse.Team == nil {
+		return nil, &NotFound{}
+	}
 
-	return &teamResponse.Team, nil
+	return teamResponse.Team, nil
 }
 
-func (c *Client) CreateTeam(team *Team) (*Team, error) {
+func (c *Client) CreateTeam(ctx context.Context, team *Team) (*Team, error) {
 
-	orgId, err := c.GetOrganizationId(c.orgSlug)
+	orgId, err := c.GetOrganizationId(ctx, c.orgSlug)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not fetch organization id")
 	}
@@ -109,14 +113,14 @@ mutation TeamNewMutation($teamCreateInput: TeamCreateInput!) {
 	})
 
 	teamCreateResponse := teamCreateResponse{}
-	if err := c.graphQLRequest(req, &teamCreateResponse); err != nil {
+	if err := c.graphQLRequest(ctx, req, &teamCreateResponse); err != nil {
 		return nil, errors.Wrapf(err, "failed to create team %s", team.Name)
 	}
 
 	return &teamCreateResponse.TeamCreate.TeamEdge.Node, nil
 }
 
-func (c *Client) UpdateTeam(team *Team) (*Team, error) {
+func (c *Client) UpdateTeam(ctx context.Context, team *Team) (*Team, error) {
 
 	req := graphql.NewRequest(`
 mutation TeamUpdateMutation($teamUpdateInput: TeamUpdateInput!) {
@@ -146,14 +150,14 @@ mutation TeamUpdateMutation($teamUpdateInput: TeamUpdateInput!) {
 	})
 
 	teamUpdateResponse := teamUpdateResponse{}
-	if err := c.graphQLRequest(req, &teamUpdateResponse); err != nil {
+	if err := c.graphQLRequest(ctx, req, &teamUpdateResponse); err != nil {
 		return nil, errors.Wrapf(err, "failed to update team %s", team.Id)
 	}
 
 	return &teamUpdateResponse.TeamUpdate.Team, nil
 }
 
-func (c *Client) DeleteTeam(id string) error {
+func (c *Client) DeleteTeam(ctx context.Context, id string) error {
 	req := graphql.NewRequest(`
 mutation TeamDeleteMutation($teamDeleteInput: TeamDeleteInput!) {
   teamDelete(input: $teamDeleteInput) {
@@ -167,7 +171,7 @@ mutation TeamDeleteMutation($teamDeleteInput: TeamDeleteInput!) {
 	})
 
 	teamDeleteResponse := teamDeleteResponse{}
-	if err := c.graphQLRequest(req, &teamDeleteResponse); err != nil {
+	if err := c.graphQLRequest(ctx, req, &teamDeleteResponse); err != nil {
 		return errors.Wrapf(err, "failed to delete team %s", id)
 	}
 
@@ -1,13 +1,14 @@
 package client
 
 import (
+	"context"
 	"github.com/machinebox/graphql"
 	"github.com/pkg/errors"
 	"log"
 )
 
 type teamMemberResponse struct {
-	TeamMember TeamMember `json:"teamMember"`
+	TeamMember *TeamMember `json:"teamMember"`
 }
 
 type TeamMember struct {
@@ -37,7 +38,7 @@ type teamMemberDeleteResponse struct {
 	DeletedTeamMemberID string `json:"deletedTeamMemberID"`
 }
 
-func (c *Client) GetTeamMember(teamMemberId string) (*TeamMember, error) {
+func (c *Client) GetTeamMember(ctx context.Context, teamMemberId string) (*TeamMember, error) {
 	log.Printf("[TRACE] Buildkite client GetTeamMember %s", teamMemberId)
 
 	req := graphql.NewRequest(`
@@ -61,14 +62,18 @@ query GetTeamMember($teamMemberId: ID!) {
 	req.Var("teamMemberId", teamMemberId)
 
 	response := teamMemberResponse{}
-	if err := c.graphQLRequest(req, &response); err != nil {
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
 		return nil, errors.Wrapf(err, "failed to get team member %s", teamMemberId)
 	}
+	// the node is null if it does not exist and empty if the id belongs to a different type
+	if response.TeamMember == nil || response.TeamMember.Id == "" {
+		return nil, &NotFound{}
+	}
 
-	return &response.TeamMember, nil
+	return response.TeamMember, nil
 }
 
-func (c *Client) CreateTeamMember(teamMember *TeamMember) (*TeamMember, error) {
+func (c *Client) CreateTeamMember(ctx context.Context, teamMember *TeamMember) (*TeamMember, error) {
 	log.Printf("[TRACE] Buildkite client CreateTeamMember %s", teamMember.UUID)
 
 	req := graphql.NewRequest(`
@@ -98,14 +103,14 @@ mutation TeamMemberNewMutation($teamMemberCreateInput: TeamMemberCreateInput!) {
 	})
 
 	response := teamMemberCreateResponse{}
-	if err := c.graphQLRequest(req, &response); err != nil {
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
 		return nil, errors.Wrapf(err, "failed to create team %s", teamMember.Team.Id)
 	}
 
 	return &response.TeamMemberCreate.TeamMemberEdge.Node, nil
 }
 
-func (c *Client) UpdateTeamMember(teamMember *TeamMember) (*TeamMember, error) {
+func (c *Client) UpdateTeamMember(ctx context.Context, teamMember *TeamMember) (*TeamMember, error) {
 
 	req := graphql.NewRequest(`
 mutation TeamMemberUpdateMutation($teamMemberUpdateInput: TeamMemberUpdateInput!) {
@@ -132,14 +137,14 @@ mutation TeamMemberUpdateMutation($teamMemberUpdateInput: TeamMemberUpdateInput!
 	})
 
 	response := teamMemberUpdateResponse{}
-	if err := c.graphQLRequest(req, &response); err != nil {
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
 		return nil, errors.Wrapf(err, "failed to update teamMember %s", teamMember.Id)
 	}
 
 	return &response.TeamMemberUpdate.TeamMember, nil
 }
 
-func (c *Client) DeleteTeamMember(teamMemberId string) error {
+func (c *Client) DeleteTeamMember(ctx context.Context, teamMemberId string) error {
 	req := graphql.NewRequest(`
 mutation TeamMemberDeleteMutation($teamMemberDeleteInput: TeamMemberDeleteInput!) {
   teamMemberDelete(input: $teamMemberDeleteInput) {
@@ -153,7 +158,7 @@ mutation TeamMemberDeleteMutation($teamMemberDeleteInput: TeamMemberDeleteInput!
 	})
 
 	response := teamMemberDeleteResponse{}
-	if err := c.graphQLRequest(req, &response); err != nil {
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
 		return errors.Wrapf(err, "failed to delete team member %s", teamMemberId)
 	}
 
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"github.com/machinebox/graphql"
 	"github.com/pkg/errors"
 	"log"
@@ -13,7 +14,7 @@ const (
 )
 
 type teamPipelineResponse struct {
-	TeamPipeline TeamPipeline `json:"teamPipeline"`
+	TeamPipeline *TeamPipeline `json:"teamPipeline"`
 }
 
 type TeamPipeline struct {
@@ -43,7 +44,7 @@ type teamPipelineDeleteResponse struct {
 	DeletedTeamPipelineID string `json:"deletedTeamPipelineID"`
 }
 
-func (c *Client) GetTeamPipeline(teamPipelineId string) (*TeamPipeline, error) {
+func (c *Client) GetTeamPipeline(ctx context.Context, teamPipelineId string) (*TeamPipeline, error) {
 	log.Printf("[TRACE] Buildkite client GetTeamPipeline %s", teamPipelineId)
 
 	req := graphql.NewRequest(`
@@ -68,17 +69,21 @@ query GetTeamPipeline($teamPipelineId: ID!) {
 	req.Var("teamPipelineId", teamPipelineId)
 
 	response := teamPipelineResponse{}
-	if err := c.graphQLRequest(req, &response); err != nil {
-		return nil, errors.Wrapf(err, "failed to get team member %s", teamPipelineId)
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to get team pipeline %s", teamPipelineId)
+	}
+	// the node is null if it does not exist and empty if the id belongs to a different type
+	if response.TeamPipeline == nil || response.TeamPipeline.Id == "" {
+		return nil, &NotFound{}
 	}
 
-	return &response.TeamPipeline, nil
+	return response.TeamPipeline, nil
 }
 
-func (c *Client) CreateTeamPipeline(teamPipeline *TeamPipeline) (*TeamPipeline, error) {
+func (c *Client) CreateTeamPipeline(ctx context.Context, teamPipeline *TeamPipeline) (*TeamPipeline, error) {
 	log.Printf("[TRACE] Buildkite client CreateTeamPipeline %s", teamPipeline.UUID)
 
-	pipelineId, err := c.GetPipelineNodeId(teamPipeline.Pipeline.Slug)
+	pipelineId, err := c.GetPipelineNodeId(ctx, teamPipeline.Pipeline.Slug)
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not get pipeline id for slug %s", teamPipeline.Pipeline.Slug)
 	}
@@ -111,14 +116,14 @@ mutation TeamPipelineNewMutation($teamPipelineCreateInput: TeamPipelineCreateInp
 	})
 
 	response := teamPipelineCreateResponse{}
-	if err := c.graphQLRequest(req, &response); err != nil {
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
 		return nil, errors.Wrapf(err, "failed to create team %s", teamPipeline.Team.Id)
 	}
 
 	return &response.TeamPipelineCreate.TeamPipelineEdge.Node, nil
 }
 
-func (c *Client) UpdateTeamPipeline(teamPipeline *TeamPipeline) (*TeamPipeline, error) {
+func (c *Client) UpdateTeamPipeline(ctx context.Context, teamPipeline *TeamPipeline) (*TeamPipeline, error) {
 
 	req := graphql.NewRequest(`
 mutation TeamPipelineUpdateMutation($teamPipelineUpdateInput: TeamPipelineUpdateInput!) {
@@ -146,14 +151,14 @@ mutation TeamPipelineUpdateMutation($teamPipelineUpdateInput: TeamPipelineUpdate
 	})
 
 	response := teamPipelineUpdateResponse{}
-	if err := c.graphQLRequest(req, &response); err != nil {
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
 		return nil, errors.Wrapf(err, "failed to update teamPipeline %s", teamPipeline.Id)
 	}
 
 	return &response.TeamPipelineUpdate.TeamPipeline, nil
 }
 
-func (c *Client) DeleteTeamPipeline(teamPipelineId string) error {
+func (c *Client) DeleteTeamPipeline(ctx context.Context, teamPipelineId string) error {
 	req := graphql.NewRequest(`
 mutation TeamPipelineDeleteMutation($teamPipelineDeleteInput: TeamPipelineDeleteInput!) {
   teamPipelineDelete(input: $teamPipelineDeleteInput) {
@@ -167,7 +172,7 @@ mutation TeamPipelineDeleteMutation($teamPipelineDeleteInput: TeamPipelineDelete
 	})
 
 	response := teamPipelineDeleteResponse{}
-	if err := c.graphQLRequest(req, &response); err != nil {
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
 		return errors.Wrapf(err, "failed to delete team member %s", teamPipelineId)
 	}
 
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"github.com/machinebox/graphql"
+	"github.com/pkg/errors"
+	"log"
+)
+
+type teamSuiteResponse struct {
+	TeamSuite *TeamSuite `json:"teamSuite"`
+}
+
+type TeamSuite struct {
+	Id          string `json:"id,omitempty"`
+	UUID        string `json:"uuid,omitempty"`
+	AccessLevel string `json:"accessLevel,omitempty"`
+	CreatedAt   string `json:"createdAt,omitempty"`
+	Team        Node   `json:"team,omitempty"`
+	Suite       Node   `json:"suite,omitempty"`
+}
+
+type teamSuiteCreateResponse struct {
+	TeamSuiteCreate struct {
+		TeamSuiteEdge struct {
+			Node TeamSuite
+		}
+	}
+}
+
+type teamSuiteUpdateResponse struct {
+	TeamSuiteUpdate struct {
+		TeamSuite TeamSuite
+	}
+}
+
+type teamSuiteDeleteResponse struct {
+	DeletedTeamSuiteID string `json:"deletedTeamSuiteID"`
+}
+
+func (c *Client) GetTeamSuite(ctx context.Context, teamSuiteId string) (*TeamSuite, error) {
+	log.Printf("[TRACE] Buildkite client GetTeamSuite %s", teamSuiteId)
+
+	req := graphql.NewRequest(`
+query GetTeamSuite($teamSuiteId: ID!) {
+  teamSuite: node(id: $teamSuiteId) {
+    ... on TeamSuite {
+      id
+      uuid
+      accessLevel
+      createdAt
+      suite {
+        id
+      }
+      team {
+        id
+      }
+    }
+  }
+}
+`)
+	req.Var("teamSuiteId", teamSuiteId)
+
+	response := teamSuiteResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to get team suite %s", teamSuiteId)
+	}
+	// the node is null if it does not exist and empty if the id belongs to a different type
+	if response.TeamSuite == nil || response.TeamSuite.Id == "" {
+		return nil, &NotFound{}
+	}
+
+	return response.TeamSuite, nil
+}
+
+// CreateTeamSuite gives the team access to the suite, unlike team pipelines the access level can be set right away
+func (c *Client) CreateTeamSuite(ctx context.Context, teamSuite *TeamSuite) (*TeamSuite, error) {
+	log.Printf("[TRACE] Buildkite client CreateTeamSuite %s %s", teamSuite.Team.Id, teamSuite.Suite.Id)
+
+	req := graphql.NewRequest(`
+mutation TeamSuiteCreateMutation($teamSuiteCreateInput: TeamSuiteCreateInput!) {
+  teamSuiteCreate(input: $teamSuiteCreateInput) {
+    teamSuiteEdge {
+      node {
+        id
+        uuid
+        accessLevel
+        createdAt
+        suite {
+          id
+        }
+        team {
+          id
+        }
+      }
+    }
+  }
+}
+`)
+	req.Var("teamSuiteCreateInput", map[string]interface{}{
+		"teamID":      teamSuite.Team.Id,
+		"suiteID":     teamSuite.Suite.Id,
+		"accessLevel": teamSuite.AccessLevel,
+	})
+
+	response := teamSuiteCreateResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to give team %s access to test suite %s", teamSuite.Team.Id, teamSuite.Suite.Id)
+	}
+
+	return &response.TeamSuiteCreate.TeamSuiteEdge.Node, nil
+}
+
+func (c *Client) UpdateTeamSuite(ctx context.Context, teamSuite *TeamSuite) (*TeamSuite, error) {
+	log.Printf("[TRACE] Buildkite client UpdateTeamSuite %s", teamSuite.Id)
+
+	req := graphql.NewRequest(`
+mutation TeamSuiteUpdateMutation($teamSuiteUpdateInput: TeamSuiteUpdateInput!) {
+  teamSuiteUpdate(input: $teamSuiteUpdateInput) {
+    teamSuite {
+      id
+      uuid
+      accessLevel
+      createdAt
+      suite {
+        id
+      }
+      team {
+        id
+      }
+    }
+  }
+}
+`)
+	req.Var("teamSuiteUpdateInput", map[string]interface{}{
+		"id":          teamSuite.Id,
+		"accessLevel": teamSuite.AccessLevel,
+	})
+
+	response := teamSuiteUpdateResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to update team suite %s", teamSuite.Id)
+	}
+
+	return &response.TeamSuiteUpdate.TeamSuite, nil
+}
+
+func (c *Client) DeleteTeamSuite(ctx context.Context, teamSuiteId string) error {
+	log.Printf("[TRACE] Buildkite client DeleteTeamSuite %s", teamSuiteId)
+
+	req := graphql.NewRequest(`
+mutation TeamSuiteDeleteMutation($teamSuiteDeleteInput: TeamSuiteDeleteInput!) {
+  teamSuiteDelete(input: $teamSuiteDeleteInput) {
+    deletedTeamSuiteID
+  }
+}
+`)
+	req.Var("teamSuiteDeleteInput", map[string]interface{}{
+		"id": teamSuiteId,
+	})
+
+	response := teamSuiteDeleteResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return errors.Wrapf(err, "failed to delete team suite %s", teamSuiteId)
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package client
+
+import (
+	"context"
+	"github.com/machinebox/graphql"
+	"github.com/pkg/errors"
+	"log"
+)
+
+const (
+	TeamSuiteAccessReadOnly = "READ_ONLY"
+	TeamSuiteAccessManage   = "MANAGE_AND_READ"
+)
+
+type testSuiteResponse struct {
+	Suite *TestSuite `json:"suite"`
+}
+
+// TestSuite is a Test Analytics suite, test collectors upload the results of a test run to it with its API token
+type TestSuite struct {
+	Id            string `json:"id,omitempty"`
+	UUID          string `json:"uuid,omitempty"`
+	Slug          string `json:"slug,omitempty"`
+	Name          string `json:"name,omitempty"`
+	DefaultBranch string `json:"defaultBranch,omitempty"`
+	URL           string `json:"url,omitempty"`
+	// TeamOwnerId is the team which is given MANAGE_AND_READ access to a new suite, it is only sent on create
+	TeamOwnerId string `json:"-"`
+	// APIToken is the token test collectors authenticate with, the API only reveals it when the suite is created
+	APIToken string `json:"-"`
+}
+
+type testSuiteCreateResponse struct {
+	SuiteCreate struct {
+		SuiteEdge struct {
+			Node TestSuite
+		}
+		APIToken string `json:"apiToken"`
+	}
+}
+
+type testSuiteUpdateResponse struct {
+	SuiteUpdate struct {
+		Suite TestSuite
+	}
+}
+
+type testSuiteDeleteResponse struct {
+	DeletedSuiteID string `json:"deletedSuiteID"`
+}
+
+func (c *Client) GetTestSuite(ctx context.Context, id string) (*TestSuite, error) {
+	log.Printf("[TRACE] Buildkite client GetTestSuite %s", id)
+
+	req := graphql.NewRequest(`
+query GetTestSuite($suiteId: ID!) {
+  suite: node(id: $suiteId) {
+    ... on Suite {
+      id
+      uuid
+      slug
+      name
+      defaultBranch
+      url
+    }
+  }
+}
+`)
+	req.Var("suiteId", id)
+
+	response := testSuiteResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to get test suite %s", id)
+	}
+	// the node is null if it does not exist and empty if the id belongs to a different type
+	if response.Suite == nil || response.Suite.Id == "" {
+		return nil, &NotFound{}
+	}
+
+	return response.Suite, nil
+}
+
+func (c *Client) CreateTestSuite(ctx context.Context, suite *TestSuite) (*TestSuite, error) {
+	log.Printf("[TRACE] Buildkite client CreateTestSuite %s", suite.Name)
+
+	orgId, err := c.GetOrganizationId(ctx, c.orgSlug)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch organization id")
+	}
+
+	req := graphql.NewRequest(`
+mutation SuiteCreateMutation($suiteCreateInput: SuiteCreateInput!) {
+  suiteCreate(input: $suiteCreateInput) {
+    suiteEdge {
+      node {
+        id
+        uuid
+        slug
+        name
+        defaultBranch
+        url
+      }
+    }
+    apiToken
+  }
+}
+`)
+	req.Var("suiteCreateInput", map[string]interface{}{
+		"organizationId": orgId,
+		"name":           suite.Name,
+		"defaultBranch":  suite.DefaultBranch,
+		"teamId":         suite.TeamOwnerId,
+	})
+
+	response := testSuiteCreateResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to create test suite %s", suite.Name)
+	}
+
+	created := response.SuiteCreate.SuiteEdge.Node
+	created.APIToken = response.SuiteCreate.APIToken
+	return &created, nil
+}
+
+func (c *Client) UpdateTestSuite(ctx context.Context, suite *TestSuite) (*TestSuite, error) {
+	log.Printf("[TRACE] Buildkite client UpdateTestSuite %s", suite.Id)
+
+	req := graphql.NewRequest(`
+mutation SuiteUpdateMutation($suiteUpdateInput: SuiteUpdateInput!) {
+  suiteUpdate(input: $suiteUpdateInput) {
+    suite {
+      id
+      uuid
+      slug
+      name
+      defaultBranch
+      url
+    }
+  }
+}
+`)
+	req.Var("suiteUpdateInput", map[string]interface{}{
+		"id":            suite.Id,
+		"name":          suite.Name,
+		"defaultBranch": suite.DefaultBranch,
+	})
+
+	response := testSuiteUpdateResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return nil, errors.Wrapf(err, "failed to update test suite %s", suite.Id)
+	}
+
+	return &response.SuiteUpdate.Suite, nil
+}
+
+func (c *Client) DeleteTestSuite(ctx context.Context, id string) error {
+	log.Printf("[TRACE] Buildkite client DeleteTestSuite %s", id)
+
+	req := graphql.NewRequest(`
+mutation SuiteDeleteMutation($suiteDeleteInput: SuiteDeleteInput!) {
+  suiteDelete(input: $suiteDeleteInput) {
+    deletedSuiteID
+  }
+}
+`)
+	req.Var("suiteDeleteInput", map[string]interface{}{
+		"id": id,
+	})
+
+	response := testSuiteDeleteResponse{}
+	if err := c.graphQLRequest(ctx, req, &response); err != nil {
+		return errors.Wrapf(err, "failed to delete test suite %s", id)
+	}
+
+	return nil
+}
+
+// GetTestSuiteTeams returns the access all teams have been given to the suite
+func (c *Client) GetTestSuiteTeams(ctx context.Context, id string) ([]TeamSuite, error) {
+	log.Printf("[TRACE] Buildkite client GetTestSuiteTeams %s", id)
+
+	query := `
+query GetTestSuiteTeams($suiteId: ID!, $first: Int!, $after: String) {
+  suite: node(id: $suiteId) {
+    ... on Suite {
+      teams(first: $first, after: $after) {
+        pageInfo {
+          hasNextPage
+          endCursor
+        }
+        edges {
+          node {
+            id
+            uuid
+            accessLevel
+            createdAt
+            team {
+              id
+            }
+            suite {
+              id
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+	teamSuites := []TeamSuite{}
+	err := c.paginate(ctx, query, map[string]interface{}{"suiteId": id}, func(req *graphql.Request) (*pageInfo, error) {
+		var resp struct {
+			Suite *struct {
+				Teams struct {
+					PageInfo pageInfo `json:"pageInfo"`
+					Edges    []struct {
+						Node TeamSuite `json:"node"`
+					} `json:"edges"`
+				} `json:"teams"`
+			} `json:"suite"`
+		}
+		if err := c.graphQLRequest(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		if resp.Suite == nil {
+			return nil, &NotFound{}
+		}
+
+		for _, edge := range resp.Suite.Teams.Edges {
+			teamSuites = append(teamSuites, edge.Node)
+		}
+		return &resp.Suite.Teams.PageInfo, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the teams of test suite %s", id)
+	}
+
+	return teamSuites, nil
+}
@@ -0,0 +1,244 @@
+package provider
+
+import (
+	_ "embed"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// suppressEquivalentPipelineConfiguration hides differences between YAML steps which only differ in their formatting,
+// e.g. because Buildkite normalised them when they were saved. The YAML read back for pipelines defining their steps
+// with steps blocks or the deprecated step and env attributes isn't configured, changes show up on those instead.
+func suppressEquivalentPipelineConfiguration(k, old, new string, d *schema.ResourceData) bool {
+	if new == "" && pipelineStepsConfigured(d) {
+		return true
+	}
+	return equivalentPipelineConfiguration(old, new)
+}
+
+// pipelineStepsConfigured reports whether the steps of the pipeline are configured by other attributes than
+// configuration
+func pipelineStepsConfigured(d *schema.ResourceData) bool {
+	for _, key := range []string{"steps", "step", "env"} {
+		if _, ok := d.GetOk(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// equivalentPipelineConfiguration reports whether both YAML documents describe the same steps, whitespace, key order,
+// quoting and comments don't matter. Documents which can't be parsed are only equivalent if they are equal.
+func equivalentPipelineConfiguration(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	var stepsA, stepsB interface{}
+	if err := yaml.Unmarshal([]byte(a), &stepsA); err != nil {
+		return false
+	}
+	if err := yaml.Unmarshal([]byte(b), &stepsB); err != nil {
+		return false
+	}
+	// an empty document means there are no steps to compare
+	if stepsA == nil || stepsB == nil {
+		return false
+	}
+
+	return reflect.DeepEqual(stepsA, stepsB)
+}
+
+// pipelineConfigurationFromAPI returns the configuration to store in the state, the configuration the way it was
+// written is kept as long as it means the same as the one Buildkite returned. Any real change of the steps then shows
+// up as a line by line difference against the configuration written by hand.
+func pipelineConfigurationFromAPI(d *schema.ResourceData, configuration string) string {
+	if previous := d.Get("configuration").(string); equivalentPipelineConfiguration(previous, configuration) {
+		return previous
+	}
+	return configuration
+}
+
+// pipelineConfigurationSchemaJSON follows the schema of pipeline YAML which Buildkite publishes at
+// https://github.com/buildkite/pipeline-schema, refresh it with scripts/update-pipeline-schema.sh
+//
+//go:embed pipeline_schema.json
+var pipelineConfigurationSchemaJSON string
+
+var pipelineConfigurationSchema = jsonschema.MustCompileString("pipeline_schema.json", pipelineConfigurationSchemaJSON)
+
+// validatePipelineConfiguration checks YAML steps against the pipeline schema, so that mistakes show up in the plan
+// instead of the first build. Terraform doesn't call it for values which are only known once applied.
+func validatePipelineConfiguration(i interface{}, k string) ([]string, []error) {
+	configuration, ok := i.(string)
+	if !ok || strings.TrimSpace(configuration) == "" {
+		return nil, nil
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(configuration), &document); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+	var steps interface{}
+	if err := document.Decode(&steps); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+
+	err := pipelineConfigurationSchema.Validate(jsonValue(steps))
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		if err != nil {
+			return nil, []error{fmt.Errorf("%s: %s", k, err)}
+		}
+		return nil, nil
+	}
+
+	var errs []error
+	for _, cause := range relevantSchemaErrors(validationErr) {
+		line, column := yamlPosition(&document, cause)
+		errs = append(errs, fmt.Errorf("%s: line %d, column %d: %s", k, line, column, cause.Message))
+	}
+	return nil, errs
+}
+
+// jsonValue converts decoded YAML into the values the JSON schema validator understands
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, element := range v {
+			v[key] = jsonValue(element)
+		}
+		return v
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			converted[fmt.Sprint(key)] = jsonValue(element)
+		}
+		return converted
+	case []interface{}:
+		for i, element := range v {
+			v[i] = jsonValue(element)
+		}
+		return v
+	case nil, bool, string, int, int64, uint64, float64:
+		return v
+	default:
+		// e.g. timestamps, Buildkite reads them as strings
+		return fmt.Sprint(v)
+	}
+}
+
+var schemaTypeErrorPattern = regexp.MustCompile(`^expected (.+), but got (.+)$`)
+
+// relevantSchemaErrors returns the errors which caused err. A step has to match one of the kinds of steps, only the
+// errors of the kind it came closest to are returned instead of the reasons it isn't any of the others.
+func relevantSchemaErrors(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+
+	if !strings.HasSuffix(err.KeywordLocation, "/anyOf") && !strings.HasSuffix(err.KeywordLocation, "/oneOf") {
+		var causes []*jsonschema.ValidationError
+		for _, cause := range err.Causes {
+			causes = append(causes, relevantSchemaErrors(cause)...)
+		}
+		return causes
+	}
+
+	// alternatives of another type don't apply to the value at all
+	var best []*jsonschema.ValidationError
+	var expectedTypes []string
+	actualType := ""
+	for _, cause := range err.Causes {
+		causes := relevantSchemaErrors(cause)
+		if len(causes) == 1 && causes[0].InstanceLocation == err.InstanceLocation {
+			if match := schemaTypeErrorPattern.FindStringSubmatch(causes[0].Message); match != nil {
+				if !contains(expectedTypes, match[1]) {
+					expectedTypes = append(expectedTypes, match[1])
+				}
+				actualType = match[2]
+				continue
+			}
+		}
+		if best == nil || len(causes) < len(best) {
+			best = causes
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	return []*jsonschema.ValidationError{{
+		KeywordLocation:  err.KeywordLocation,
+		InstanceLocation: err.InstanceLocation,
+		Message:          fmt.Sprintf("expected %s, but got %s", strings.Join(expectedTypes, " or "), actualType),
+	}}
+}
+
+var schemaAdditionalPropertiesPattern = regexp.MustCompile(`^additionalProperties '([^']*)'`)
+
+// yamlPosition returns the line and column of the YAML value the error is about, properties which are not allowed
+// are pointed at by their key
+func yamlPosition(document *yaml.Node, err *jsonschema.ValidationError) (int, int) {
+	node := document
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	if err.InstanceLocation != "" {
+		for _, segment := range strings.Split(strings.TrimPrefix(err.InstanceLocation, "/"), "/") {
+			segment = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+			_, value := yamlChild(node, segment)
+			if value == nil {
+				return node.Line, node.Column
+			}
+			node = value
+		}
+	}
+
+	if match := schemaAdditionalPropertiesPattern.FindStringSubmatch(err.Message); match != nil {
+		if key, _ := yamlChild(node, match[1]); key != nil {
+			node = key
+		}
+	}
+	return node.Line, node.Column
+}
+
+// yamlChild returns the key and the value of a mapping entry, or the element of a sequence as both, the path segment
+// refers to
+func yamlChild(node *yaml.Node, segment string) (*yaml.Node, *yaml.Node) {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 || index >= len(node.Content) {
+			return nil, nil
+		}
+		return node.Content[index], node.Content[index]
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment {
+				return node.Content[i], node.Content[i+1]
+			}
+		}
+		// the key may come from a merged anchor, e.g. <<: *defaults
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "<<" {
+				if key, value := yamlChild(node.Content[i+1], segment); value != nil {
+					return key, value
+				}
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestEquivalentPipelineConfiguration(t *testing.T) {
+	cases := []struct {
+		name       string
+		a, b       string
+		equivalent bool
+	}{
+		{"equal", "steps:\n  - command: make\n", "steps:\n  - command: make\n", true},
+		{"indentation", "steps:\n    - command: make\n", "steps:\n  - command: make\n", true},
+		{"quoting", "steps:\n  - command: \"make\"\n", "steps:\n  - command: make\n", true},
+		{"key order", "steps:\n  - label: Build\n    command: make\n", "steps:\n  - command: make\n    label: Build\n", true},
+		{"comments", "# build it\nsteps:\n  - command: make # all\n", "steps:\n  - command: make\n", true},
+		{"changed command", "steps:\n  - command: make\n", "steps:\n  - command: make test\n", false},
+		{"step order", "steps:\n  - command: a\n  - command: b\n", "steps:\n  - command: b\n  - command: a\n", false},
+		{"number and string", "steps:\n  - parallelism: 2\n", "steps:\n  - parallelism: \"2\"\n", false},
+		{"invalid", "steps: [", "steps: [\n", false},
+		{"empty", "", "steps:\n  - command: make\n", false},
+	}
+
+	for _, c := range cases {
+		if equivalent := equivalentPipelineConfiguration(c.a, c.b); equivalent != c.equivalent {
+			t.Errorf("%s: expected equivalent to be %t, got %t", c.name, c.equivalent, equivalent)
+		}
+	}
+}
+
+func TestValidatePipelineConfiguration(t *testing.T) {
+	cases := []struct {
+		name          string
+		configuration string
+		errors        []string
+	}{
+		{"valid", "steps:\n  - command: make\n  - wait\n  - block: Deploy?\n  - trigger: deploy\n", nil},
+		{"anchors", "x-defaults: &defaults\n  agents:\n    queue: test\nsteps:\n  - <<: *defaults\n    command: make\n", nil},
+		{"empty", "", nil},
+		{"invalid yaml", "steps: [\n", []string{"configuration: yaml: line 1: did not find expected node content"}},
+		{"typo", "steps:\n  - label: Tests\n    comand: make test\n", []string{
+			"configuration: line 3, column 5: additionalProperties 'comand' not allowed",
+		}},
+		{"wrong type", "steps:\n  - command: make\n    parallelism: two\n", []string{
+			"configuration: line 3, column 18: expected integer, but got string",
+		}},
+		{"nested", "steps:\n  - group: Tests\n    steps:\n      - block: Go?\n        blocked_state: maybe\n", []string{
+			`configuration: line 5, column 24: value must be one of "passed", "failed", "running"`,
+		}},
+		{"step kind", "steps:\n  - 3\n", []string{"configuration: line 2, column 5: expected string or object, but got number"}},
+		{"no steps", "env:\n  CI: true\n", []string{"configuration: line 1, column 1: missing properties: 'steps'"}},
+	}
+
+	for _, c := range cases {
+		_, errs := validatePipelineConfiguration(c.configuration, "configuration")
+		if len(errs) != len(c.errors) {
+			t.Errorf("%s: expected %d errors, got %v", c.name, len(c.errors), errs)
+			continue
+		}
+		for i, err := range errs {
+			if err.Error() != c.errors[i] {
+				t.Errorf("%s: expected %q, got %q", c.name, c.errors[i], err.Error())
+			}
+		}
+	}
+}
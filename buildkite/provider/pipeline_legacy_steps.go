@@ -0,0 +1,434 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"gopkg.in/yaml.v3"
+
+	"github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+// resourcePipelineV0 is the schema of pipelines before their legacy steps were also shown as YAML in configuration.
+// It only serves to read states written by earlier versions, defaults, conflicts and deprecations are left out.
+func resourcePipelineV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"slug": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"web_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"builds_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"badge_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"branch_configuration": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"default_branch": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"env": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"webhook_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"configuration": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"team_ids": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"step": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"command": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"env": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"timeout_in_minutes": {
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+						"agent_query_rules": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"artifact_paths": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"branch_configuration": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"concurrency": {
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+						"parallelism": {
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"bitbucket_settings": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"trigger_mode": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"build_pull_requests": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"pull_request_branch_filter_enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"pull_request_branch_filter_configuration": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"skip_pull_request_builds_for_existing_commits": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"prefix_pull_request_fork_branch_names": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"build_tags": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"publish_commit_status": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"publish_commit_status_per_step": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"github_settings": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"trigger_mode": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"build_pull_requests": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"pull_request_branch_filter_enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"pull_request_branch_filter_configuration": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"skip_pull_request_builds_for_existing_commits": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"build_pull_request_forks": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"prefix_pull_request_fork_branch_names": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"build_tags": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"publish_commit_status": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"publish_commit_status_per_step": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"publish_blocked_as_pending": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"separate_pull_request_statuses": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"filter_enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// upgradePipelineStateV0 fills the configuration of pipelines using the deprecated step and env attributes with the
+// equivalent YAML, so that they can be replaced by it without changing the steps of the pipeline
+func upgradePipelineStateV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	log.Printf("[TRACE] upgradePipelineStateV0")
+
+	if _, ok := rawState["migrate_legacy_steps"]; !ok {
+		rawState["migrate_legacy_steps"] = false
+	}
+	if stringValue(rawState["configuration"]) != "" || stringValue(rawState["pipeline_template_id"]) != "" {
+		return rawState, nil
+	}
+
+	steps := legacyPipelineSteps(listValue(rawState["step"]))
+	env := stringValues(rawState["env"])
+	if len(steps) == 0 && len(env) == 0 {
+		return rawState, nil
+	}
+
+	configuration, err := renderLegacyPipelineSteps(steps, env)
+	if err != nil {
+		// the pipeline keeps working with its legacy steps, it just can't be migrated
+		log.Printf("[WARN] buildkite: the steps of pipeline %v can't be converted to YAML: %s", rawState["slug"], err)
+		return rawState, nil
+	}
+	rawState["configuration"] = configuration
+
+	return rawState, nil
+}
+
+// legacyPipelineSteps reads the deprecated step blocks
+func legacyPipelineSteps(stepsI []interface{}) []client.Step {
+	steps := make([]client.Step, len(stepsI))
+	for i, stepI := range stepsI {
+		stepM := stringMap(stepI)
+		steps[i] = client.Step{
+			Type:                stringValue(stepM["type"]),
+			Name:                stringValue(stepM["name"]),
+			Command:             stringValue(stepM["command"]),
+			Environment:         map[string]string{},
+			AgentQueryRules:     []string{},
+			BranchConfiguration: stringValue(stepM["branch_configuration"]),
+			ArtifactPaths:       stringValue(stepM["artifact_paths"]),
+			Concurrency:         intValue(stepM["concurrency"]),
+			Parallelism:         intValue(stepM["parallelism"]),
+			TimeoutInMinutes:    intValue(stepM["timeout_in_minutes"]),
+		}
+
+		for k, v := range stringValues(stepM["env"]) {
+			steps[i].Environment[k] = v
+		}
+		steps[i].AgentQueryRules = append(steps[i].AgentQueryRules, stringList(stepM["agent_query_rules"])...)
+	}
+	return steps
+}
+
+type legacyPipelineDocument struct {
+	Env   map[string]string `yaml:"env,omitempty"`
+	Steps []interface{}     `yaml:"steps"`
+}
+
+// renderLegacyPipelineSteps converts the steps and environment of a pipeline which doesn't use YAML yet into the
+// equivalent YAML configuration
+func renderLegacyPipelineSteps(steps []client.Step, env map[string]string) (string, error) {
+	document := legacyPipelineDocument{
+		Env:   env,
+		Steps: []interface{}{},
+	}
+	for i, step := range steps {
+		rendered, err := renderLegacyStep(step)
+		if err != nil {
+			return "", fmt.Errorf("step %d: %s", i, err)
+		}
+		document.Steps = append(document.Steps, rendered)
+	}
+
+	var configuration bytes.Buffer
+	encoder := yaml.NewEncoder(&configuration)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return configuration.String(), nil
+}
+
+func renderLegacyStep(step client.Step) (interface{}, error) {
+	dependencies := stepDependencies{Branches: step.BranchConfiguration}
+
+	switch step.Type {
+	case "script":
+		rendered := commandStep{
+			Label:            step.Name,
+			stepDependencies: dependencies,
+			TimeoutInMinutes: step.TimeoutInMinutes,
+			Concurrency:      step.Concurrency,
+			Parallelism:      step.Parallelism,
+		}
+		if step.Command != "" {
+			rendered.Command = step.Command
+		}
+		if len(step.Environment) > 0 {
+			rendered.Env = step.Environment
+		}
+		// legacy steps separate their artifact paths by semicolons
+		for _, path := range strings.Split(step.ArtifactPaths, ";") {
+			if path = strings.TrimSpace(path); path != "" {
+				rendered.ArtifactPaths = append(rendered.ArtifactPaths, path)
+			}
+		}
+		for _, rule := range step.AgentQueryRules {
+			parts := strings.SplitN(rule, "=", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("agent query rule %q is not of the form key=value", rule)
+			}
+			if rendered.Agents == nil {
+				rendered.Agents = map[string]string{}
+			}
+			rendered.Agents[parts[0]] = parts[1]
+		}
+		return rendered, nil
+
+	case "waiter":
+		if dependencies.empty() {
+			return "wait", nil
+		}
+		return waitStep{stepDependencies: dependencies}, nil
+
+	case "manual":
+		if step.Name == "" && dependencies.empty() {
+			return "block", nil
+		}
+		label := step.Name
+		if label == "" {
+			label = "Unblock"
+		}
+		return blockStep{Block: label, stepDependencies: dependencies}, nil
+	}
+
+	return nil, fmt.Errorf("%s steps can't be converted to YAML", step.Type)
+}
+
+// legacyPipelineConfiguration returns the YAML equivalent of the deprecated step and env attributes, if they are used
+func legacyPipelineConfiguration(steps []interface{}, env map[string]interface{}) (string, bool, error) {
+	if len(steps) == 0 && len(env) == 0 {
+		return "", false, nil
+	}
+	configuration, err := renderLegacyPipelineSteps(legacyPipelineSteps(steps), stringValues(env))
+	return configuration, true, err
+}
+
+// customizeLegacyPipelineDiff checks that the deprecated step and env attributes can be converted to YAML, pipelines
+// which are migrated to YAML fail to plan if their steps can't be converted
+func customizeLegacyPipelineDiff(d *schema.ResourceDiff) error {
+	steps, _ := d.Get("step").([]interface{})
+	env, _ := d.Get("env").(map[string]interface{})
+	if len(steps) == 0 && len(env) == 0 {
+		return nil
+	}
+	if !pipelineStepsKnown(d, "step", steps) || !pipelineStepsKnown(d, "env", env) {
+		return nil
+	}
+
+	if _, _, err := legacyPipelineConfiguration(steps, env); err != nil {
+		if d.Get("migrate_legacy_steps").(bool) {
+			return fmt.Errorf("the steps of the pipeline can't be migrated: %s", err)
+		}
+		log.Printf("[WARN] buildkite: the steps of the pipeline can't be converted to YAML: %s", err)
+	}
+	return nil
+}
+
+// migratedLegacySteps reports whether the pipeline was migrated from the step and env attributes in the state to
+// YAML steps, which are still equivalent to them
+func migratedLegacySteps(d *schema.ResourceData, p *client.Pipeline) bool {
+	if !d.Get("migrate_legacy_steps").(bool) || len(p.Steps) != 0 || p.Configuration == "" {
+		return false
+	}
+
+	configuration, ok, err := legacyPipelineConfiguration(d.Get("step").([]interface{}), d.Get("env").(map[string]interface{}))
+	return ok && err == nil && equivalentPipelineConfiguration(configuration, p.Configuration)
+}
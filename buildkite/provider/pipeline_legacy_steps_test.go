@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+func TestRenderLegacyPipelineSteps(t *testing.T) {
+	cases := []struct {
+		name          string
+		steps         []client.Step
+		env           map[string]string
+		configuration string
+		err           string
+	}{
+		{
+			name: "script",
+			steps: []client.Step{{
+				Type:             "script",
+				Name:             "Tests",
+				Command:          "make test",
+				Environment:      map[string]string{"CI": "true"},
+				AgentQueryRules:  []string{"queue=default", "os=linux"},
+				ArtifactPaths:    "coverage/*; logs/*",
+				TimeoutInMinutes: 10,
+			}},
+			env: map[string]string{"DEBUG": "1"},
+			configuration: `env:
+  DEBUG: "1"
+steps:
+  - label: Tests
+    command: make test
+    agents:
+      os: linux
+      queue: default
+    env:
+      CI: "true"
+    timeout_in_minutes: 10
+    artifact_paths:
+      - coverage/*
+      - logs/*
+`,
+		},
+		{
+			name: "waiter and manual",
+			steps: []client.Step{
+				{Type: "waiter"},
+				{Type: "manual"},
+				{Type: "waiter", BranchConfiguration: "main"},
+				{Type: "manual", Name: "Deploy?", BranchConfiguration: "main"},
+			},
+			configuration: `steps:
+  - wait
+  - block
+  - wait: null
+    branches: main
+  - block: Deploy?
+    branches: main
+`,
+		},
+		{
+			name:          "no steps",
+			configuration: "steps: []\n",
+		},
+		{
+			name:  "agent query rule",
+			steps: []client.Step{{Type: "script", AgentQueryRules: []string{"linux"}}},
+			err:   `step 0: agent query rule "linux" is not of the form key=value`,
+		},
+		{
+			name:  "trigger",
+			steps: []client.Step{{Type: "waiter"}, {Type: "trigger"}},
+			err:   "step 1: trigger steps can't be converted to YAML",
+		},
+	}
+
+	for _, c := range cases {
+		configuration, err := renderLegacyPipelineSteps(c.steps, c.env)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("%s: expected error %q, got %v", c.name, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", c.name, err)
+			continue
+		}
+		if configuration != c.configuration {
+			t.Errorf("%s: expected configuration\n%s\ngot\n%s", c.name, c.configuration, configuration)
+		}
+	}
+}
+
+func TestUpgradePipelineStateV0(t *testing.T) {
+	cases := []struct {
+		name          string
+		state         map[string]interface{}
+		configuration interface{}
+	}{
+		{
+			name: "legacy steps",
+			state: map[string]interface{}{
+				"slug": "legacy",
+				"env":  map[string]interface{}{"DEBUG": "1"},
+				"step": []interface{}{
+					map[string]interface{}{
+						"type":               "script",
+						"name":               "Tests",
+						"command":            "make test",
+						"agent_query_rules":  []interface{}{"queue=default"},
+						"timeout_in_minutes": float64(10),
+					},
+					map[string]interface{}{"type": "waiter"},
+				},
+			},
+			configuration: "env:\n  DEBUG: \"1\"\nsteps:\n  - label: Tests\n    command: make test\n    agents:\n      queue: default\n    timeout_in_minutes: 10\n  - wait\n",
+		},
+		{
+			name: "yaml steps",
+			state: map[string]interface{}{
+				"slug":          "yaml",
+				"configuration": "steps:\n  - command: make test\n",
+			},
+			configuration: "steps:\n  - command: make test\n",
+		},
+		{
+			name: "template",
+			state: map[string]interface{}{
+				"slug":                 "template",
+				"pipeline_template_id": "UGlwZWxpbmVUZW1wbGF0ZS0tLTE=",
+				"step":                 []interface{}{},
+			},
+			configuration: nil,
+		},
+		{
+			name: "unconvertible steps",
+			state: map[string]interface{}{
+				"slug": "trigger",
+				"step": []interface{}{map[string]interface{}{"type": "trigger"}},
+			},
+			configuration: nil,
+		},
+	}
+
+	for _, c := range cases {
+		state, err := upgradePipelineStateV0(c.state, nil)
+		if err != nil {
+			t.Errorf("%s: %s", c.name, err)
+			continue
+		}
+		if state["configuration"] != c.configuration {
+			t.Errorf("%s: expected configuration %q, got %q", c.name, c.configuration, state["configuration"])
+		}
+		if state["migrate_legacy_steps"] != false {
+			t.Errorf("%s: expected migrate_legacy_steps to be false, got %v", c.name, state["migrate_legacy_steps"])
+		}
+	}
+}
+
+func TestResourcePipelineV0(t *testing.T) {
+	block := resourcePipelineV0().CoreConfigSchema()
+	for _, attribute := range []string{"slug", "configuration", "env", "team_ids"} {
+		if _, ok := block.Attributes[attribute]; !ok {
+			t.Errorf("expected the v0 schema to have %s", attribute)
+		}
+	}
+	for _, attribute := range []string{"steps", "team", "migrate_legacy_steps", "cluster_id", "pipeline_template_id", "provider_settings"} {
+		if _, ok := block.Attributes[attribute]; ok {
+			t.Errorf("expected the v0 schema not to have %s, it was added later", attribute)
+		}
+	}
+	for _, blockType := range []string{"steps", "team", "gitlab_settings", "github_enterprise_settings"} {
+		if _, ok := block.BlockTypes[blockType]; ok {
+			t.Errorf("expected the v0 schema not to have %s blocks, they were added later", blockType)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// repositoryProviderSettings are the settings blocks of the repository providers Buildkite recognises by their host,
+// the hosts of GitHub Enterprise and self-managed GitLab are only known to Buildkite
+var repositoryProviderSettings = map[string]string{
+	"github.com":    "github_settings",
+	"bitbucket.org": "bitbucket_settings",
+	"gitlab.com":    "gitlab_settings",
+}
+
+// customizeProviderSettingsDiff rejects provider_settings which the settings block of the repository provider has,
+// the block and the map would overwrite each other
+func customizeProviderSettingsDiff(d *schema.ResourceDiff, meta interface{}) error {
+	settings := d.Get("provider_settings").(map[string]interface{})
+	name := activeProviderSettings(d)
+	if len(settings) == 0 || name == "" {
+		return nil
+	}
+
+	attributes := pipelineSchema[name].Elem.(*schema.Resource).Schema
+	duplicates := []string{}
+	for key := range settings {
+		if _, ok := attributes[key]; ok {
+			duplicates = append(duplicates, key)
+		}
+	}
+	if len(duplicates) > 0 {
+		sort.Strings(duplicates)
+		return fmt.Errorf("provider_settings: %s can't be given, use %s instead", strings.Join(duplicates, ", "), name)
+	}
+	return nil
+}
+
+// activeProviderSettings returns the settings block of the repository provider of the pipeline, if it is known. The
+// blocks are read back for the provider Buildkite recognised, so they only count if the repository doesn't tell.
+func activeProviderSettings(d *schema.ResourceDiff) string {
+	repository := d.Get("repository").(string)
+	for host, name := range repositoryProviderSettings {
+		if strings.Contains(repository, host) {
+			return name
+		}
+	}
+	for _, name := range providerSettingsAttributes {
+		if blocks, _ := d.Get(name).([]interface{}); len(blocks) > 0 && blocks[0] != nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// providerSettingValue returns the value of a setting given by provider_settings the way the REST API expects it,
+// booleans, numbers, lists and objects are given as JSON
+func providerSettingValue(value string) interface{} {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil || decoder.More() {
+		return value
+	}
+	switch decoded.(type) {
+	case bool, json.Number, []interface{}, map[string]interface{}:
+		return decoded
+	}
+	return value
+}
+
+// providerSettingFromAPI returns a setting read from the REST API the way provider_settings gives it. The previous
+// value is kept if it's JSON for the same value, e.g. with other whitespace.
+func providerSettingFromAPI(previous string, value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	setting := strings.TrimSuffix(encoded.String(), "\n")
+
+	if previous != "" {
+		var a, b interface{}
+		if json.Unmarshal([]byte(previous), &a) == nil && json.Unmarshal([]byte(setting), &b) == nil && reflect.DeepEqual(a, b) {
+			return previous, nil
+		}
+	}
+	return setting, nil
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestProviderSettingValue(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		sent  interface{}
+	}{
+		{"string", "build.branch == 'main'", "build.branch == 'main'"},
+		{"empty", "", ""},
+		{"boolean", "false", false},
+		{"integer", "10", json.Number("10")},
+		{"float", "1.5", json.Number("1.5")},
+		{"list", `["main","release/*"]`, []interface{}{"main", "release/*"}},
+		{"object", `{"enabled":true}`, map[string]interface{}{"enabled": true}},
+		{"json string", `"main"`, `"main"`},
+		{"null", "null", "null"},
+		{"several values", "1 2", "1 2"},
+	}
+
+	for _, c := range cases {
+		if sent := providerSettingValue(c.value); !reflect.DeepEqual(sent, c.sent) {
+			t.Errorf("%s: expected %#v to be sent, got %#v", c.name, c.sent, sent)
+		}
+	}
+}
+
+func TestProviderSettingFromAPI(t *testing.T) {
+	cases := []struct {
+		name     string
+		previous string
+		value    interface{}
+		setting  string
+	}{
+		{"string", "", "build.branch == 'main'", "build.branch == 'main'"},
+		{"boolean", "", false, "false"},
+		{"integer", "", float64(10), "10"},
+		{"float", "", 1.5, "1.5"},
+		{"list", "", []interface{}{"main", "release/*"}, `["main","release/*"]`},
+		{"object", "", map[string]interface{}{"enabled": true, "branch": "main"}, `{"branch":"main","enabled":true}`},
+		{"same json", `[ "main", "release/*" ]`, []interface{}{"main", "release/*"}, `[ "main", "release/*" ]`},
+		{"same number", "10.0", float64(10), "10.0"},
+		{"changed json", `["main"]`, []interface{}{"main", "release/*"}, `["main","release/*"]`},
+	}
+
+	for _, c := range cases {
+		setting, err := providerSettingFromAPI(c.previous, c.value)
+		if err != nil {
+			t.Errorf("%s: %s", c.name, err)
+			continue
+		}
+		if setting != c.setting {
+			t.Errorf("%s: expected %q, got %q", c.name, c.setting, setting)
+		}
+	}
+}
@@ -0,0 +1,790 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"gopkg.in/yaml.v3"
+)
+
+// pipelineStepKinds are the blocks of a steps block, each steps block holds exactly one of them
+var pipelineStepKinds = []string{"command", "wait", "block", "input", "trigger", "group"}
+
+// pipelineStepsSchema describes a single step. Steps of a group can't be groups themselves.
+func pipelineStepsSchema(inGroup bool) *schema.Resource {
+	kinds := map[string]*schema.Schema{
+		"command": pipelineStepKindSchema(commandStepSchema()),
+		"wait":    pipelineStepKindSchema(waitStepSchema()),
+		"block":   pipelineStepKindSchema(blockStepSchema(true)),
+		"input":   pipelineStepKindSchema(blockStepSchema(false)),
+		"trigger": pipelineStepKindSchema(triggerStepSchema()),
+	}
+	if !inGroup {
+		kinds["group"] = pipelineStepKindSchema(groupStepSchema())
+	}
+	return &schema.Resource{Schema: kinds}
+}
+
+func pipelineStepKindSchema(fields map[string]*schema.Schema) *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem:     &schema.Resource{Schema: withStepDependencies(fields)},
+	}
+}
+
+// withStepDependencies adds the arguments every kind of step has to decide whether and when it runs
+func withStepDependencies(fields map[string]*schema.Schema) map[string]*schema.Schema {
+	fields["key"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+	}
+	fields["depends_on"] = &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+	}
+	fields["allow_dependency_failure"] = &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+	}
+	fields["if"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+	}
+	fields["branches"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+	}
+	return fields
+}
+
+func commandStepSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"label": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"commands": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"agents": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"env": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"parallelism": {
+			Type:     schema.TypeInt,
+			Optional: true,
+		},
+		"timeout_in_minutes": {
+			Type:     schema.TypeInt,
+			Optional: true,
+		},
+		"artifact_paths": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"concurrency": {
+			Type:     schema.TypeInt,
+			Optional: true,
+		},
+		"concurrency_group": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		// plugins run in the order of the blocks, the configuration is JSON so that it can take any shape
+		"plugin": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"configuration": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringIsJSON,
+					},
+				},
+			},
+		},
+		"retry": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"automatic": {
+						Type:     schema.TypeList,
+						Optional: true,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"exit_status": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"limit": {
+									Type:     schema.TypeInt,
+									Optional: true,
+								},
+								"signal_reason": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+							},
+						},
+					},
+					"manual": {
+						Type:     schema.TypeList,
+						Optional: true,
+						MaxItems: 1,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"allowed": {
+									Type:     schema.TypeBool,
+									Optional: true,
+									Default:  true,
+								},
+								"permit_on_passed": {
+									Type:     schema.TypeBool,
+									Optional: true,
+								},
+								"reason": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"soft_fail": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"exit_status": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func waitStepSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"continue_on_failure": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+	}
+}
+
+// block and input steps only differ in whether the build shows as blocked while it waits
+func blockStepSchema(block bool) map[string]*schema.Schema {
+	fields := map[string]*schema.Schema{
+		"label": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"prompt": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"field": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"key": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"text": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"select": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"hint": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"required": {
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  true,
+					},
+					"default": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"multiple": {
+						Type:     schema.TypeBool,
+						Optional: true,
+					},
+					"option": {
+						Type:     schema.TypeList,
+						Optional: true,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"label": {
+									Type:     schema.TypeString,
+									Required: true,
+								},
+								"value": {
+									Type:     schema.TypeString,
+									Required: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if block {
+		fields["blocked_state"] = &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice([]string{"passed", "failed", "running"}, false),
+		}
+	}
+	return fields
+}
+
+func triggerStepSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"label": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"pipeline": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"async": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"build": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"branch": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"commit": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"message": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"env": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem: &schema.Schema{
+							Type: schema.TypeString,
+						},
+					},
+					"meta_data": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem: &schema.Schema{
+							Type: schema.TypeString,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func groupStepSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"label": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"steps": {
+			Type:     schema.TypeList,
+			Required: true,
+			MinItems: 1,
+			Elem:     pipelineStepsSchema(true),
+		},
+	}
+}
+
+// customizePipelineDiff validates the YAML the steps blocks of a pipeline render to, so that mistakes show up in the
+// plan. It can only be checked once all values of the steps are known.
+func customizePipelineDiff(d *schema.ResourceDiff, meta interface{}) error {
+	steps, ok := d.GetOk("steps")
+	if !ok {
+		return customizeLegacyPipelineDiff(d)
+	}
+	if !pipelineStepsKnown(d, "steps", steps) {
+		return nil
+	}
+
+	configuration, err := renderPipelineSteps(steps.([]interface{}))
+	if err != nil {
+		return err
+	}
+	if _, errs := validatePipelineConfiguration(configuration, "steps"); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// pipelineStepsKnown reports whether the value at key and all values nested in it are known. Terraform only marks
+// the values which are unknown themselves, not the lists and maps holding them.
+func pipelineStepsKnown(d *schema.ResourceDiff, key string, value interface{}) bool {
+	if !d.NewValueKnown(key) {
+		return false
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		for i, element := range v {
+			if !pipelineStepsKnown(d, fmt.Sprintf("%s.%d", key, i), element) {
+				return false
+			}
+		}
+	case map[string]interface{}:
+		for k, element := range v {
+			if !pipelineStepsKnown(d, key+"."+k, element) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type pipelineStepsDocument struct {
+	Steps []interface{} `yaml:"steps"`
+}
+
+type stepDependencies struct {
+	Key                    string   `yaml:"key,omitempty"`
+	DependsOn              []string `yaml:"depends_on,omitempty"`
+	AllowDependencyFailure bool     `yaml:"allow_dependency_failure,omitempty"`
+	If                     string   `yaml:"if,omitempty"`
+	Branches               string   `yaml:"branches,omitempty"`
+}
+
+type commandStep struct {
+	Label            string      `yaml:"label,omitempty"`
+	Command          interface{} `yaml:"command,omitempty"`
+	stepDependencies `yaml:",inline"`
+	Plugins          []interface{}     `yaml:"plugins,omitempty"`
+	Agents           map[string]string `yaml:"agents,omitempty"`
+	Env              map[string]string `yaml:"env,omitempty"`
+	Parallelism      int               `yaml:"parallelism,omitempty"`
+	TimeoutInMinutes int               `yaml:"timeout_in_minutes,omitempty"`
+	ArtifactPaths    []string          `yaml:"artifact_paths,omitempty"`
+	Concurrency      int               `yaml:"concurrency,omitempty"`
+	ConcurrencyGroup string            `yaml:"concurrency_group,omitempty"`
+	Retry            *commandRetry     `yaml:"retry,omitempty"`
+	SoftFail         []softFail        `yaml:"soft_fail,omitempty"`
+}
+
+type commandRetry struct {
+	Automatic interface{} `yaml:"automatic,omitempty"`
+	Manual    interface{} `yaml:"manual,omitempty"`
+}
+
+type automaticRetry struct {
+	ExitStatus   interface{} `yaml:"exit_status,omitempty"`
+	Limit        int         `yaml:"limit,omitempty"`
+	SignalReason string      `yaml:"signal_reason,omitempty"`
+}
+
+type manualRetry struct {
+	Allowed        *bool  `yaml:"allowed,omitempty"`
+	PermitOnPassed bool   `yaml:"permit_on_passed,omitempty"`
+	Reason         string `yaml:"reason,omitempty"`
+}
+
+type softFail struct {
+	ExitStatus interface{} `yaml:"exit_status"`
+}
+
+type waitStep struct {
+	Wait              interface{} `yaml:"wait"`
+	ContinueOnFailure bool        `yaml:"continue_on_failure,omitempty"`
+	stepDependencies  `yaml:",inline"`
+}
+
+type blockStep struct {
+	Block            string `yaml:"block,omitempty"`
+	Input            string `yaml:"input,omitempty"`
+	stepDependencies `yaml:",inline"`
+	Prompt           string       `yaml:"prompt,omitempty"`
+	Fields           []inputField `yaml:"fields,omitempty"`
+	BlockedState     string       `yaml:"blocked_state,omitempty"`
+}
+
+type inputField struct {
+	Text     string             `yaml:"text,omitempty"`
+	Select   string             `yaml:"select,omitempty"`
+	Key      string             `yaml:"key"`
+	Hint     string             `yaml:"hint,omitempty"`
+	Required *bool              `yaml:"required,omitempty"`
+	Default  string             `yaml:"default,omitempty"`
+	Multiple bool               `yaml:"multiple,omitempty"`
+	Options  []inputFieldOption `yaml:"options,omitempty"`
+}
+
+type inputFieldOption struct {
+	Label string `yaml:"label"`
+	Value string `yaml:"value"`
+}
+
+type triggerStep struct {
+	Label            string `yaml:"label,omitempty"`
+	Trigger          string `yaml:"trigger"`
+	stepDependencies `yaml:",inline"`
+	Async            bool          `yaml:"async,omitempty"`
+	Build            *triggerBuild `yaml:"build,omitempty"`
+}
+
+type triggerBuild struct {
+	Branch   string            `yaml:"branch,omitempty"`
+	Commit   string            `yaml:"commit,omitempty"`
+	Message  string            `yaml:"message,omitempty"`
+	Env      map[string]string `yaml:"env,omitempty"`
+	MetaData map[string]string `yaml:"meta_data,omitempty"`
+}
+
+type groupStep struct {
+	Group            string `yaml:"group"`
+	stepDependencies `yaml:",inline"`
+	Steps            []interface{} `yaml:"steps"`
+}
+
+// renderPipelineSteps serialises the steps blocks into the YAML configuration of the pipeline
+func renderPipelineSteps(stepsI []interface{}) (string, error) {
+	steps, err := renderSteps("steps", stepsI)
+	if err != nil {
+		return "", err
+	}
+
+	var configuration bytes.Buffer
+	encoder := yaml.NewEncoder(&configuration)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(pipelineStepsDocument{Steps: steps}); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return configuration.String(), nil
+}
+
+func renderSteps(path string, stepsI []interface{}) ([]interface{}, error) {
+	steps := make([]interface{}, len(stepsI))
+	for i, stepI := range stepsI {
+		step, err := renderStep(fmt.Sprintf("%s.%d", path, i), stringMap(stepI))
+		if err != nil {
+			return nil, err
+		}
+		steps[i] = step
+	}
+	return steps, nil
+}
+
+func renderStep(path string, stepM map[string]interface{}) (interface{}, error) {
+	var kinds []string
+	for _, kind := range pipelineStepKinds {
+		if blocks, ok := stepM[kind].([]interface{}); ok && len(blocks) > 0 {
+			kinds = append(kinds, kind)
+		}
+	}
+	if len(kinds) != 1 {
+		return nil, fmt.Errorf("%s: a step needs exactly one of %s, got %d", path, strings.Join(pipelineStepKinds, ", "), len(kinds))
+	}
+
+	kind := kinds[0]
+	m := stringMap(stepM[kind].([]interface{})[0])
+	dependencies := renderStepDependencies(m)
+
+	switch kind {
+	case "command":
+		return renderCommandStep(m, dependencies), nil
+
+	case "wait":
+		step := waitStep{
+			ContinueOnFailure: m["continue_on_failure"] == true,
+			stepDependencies:  dependencies,
+		}
+		if !step.ContinueOnFailure && dependencies.empty() {
+			return "wait", nil
+		}
+		return step, nil
+
+	case "block", "input":
+		step := blockStep{
+			stepDependencies: dependencies,
+			Prompt:           stringValue(m["prompt"]),
+			BlockedState:     stringValue(m["blocked_state"]),
+		}
+		if kind == "block" {
+			step.Block = stringValue(m["label"])
+		} else {
+			step.Input = stringValue(m["label"])
+		}
+		for i, fieldI := range listValue(m["field"]) {
+			field, err := renderInputField(fmt.Sprintf("%s.%s.0.field.%d", path, kind, i), stringMap(fieldI))
+			if err != nil {
+				return nil, err
+			}
+			step.Fields = append(step.Fields, field)
+		}
+		return step, nil
+
+	case "trigger":
+		step := triggerStep{
+			Label:            stringValue(m["label"]),
+			Trigger:          stringValue(m["pipeline"]),
+			stepDependencies: dependencies,
+			Async:            m["async"] == true,
+		}
+		if builds := listValue(m["build"]); len(builds) > 0 {
+			build := stringMap(builds[0])
+			step.Build = &triggerBuild{
+				Branch:   stringValue(build["branch"]),
+				Commit:   stringValue(build["commit"]),
+				Message:  stringValue(build["message"]),
+				Env:      stringValues(build["env"]),
+				MetaData: stringValues(build["meta_data"]),
+			}
+		}
+		return step, nil
+
+	case "group":
+		steps, err := renderSteps(path+".group.0.steps", listValue(m["steps"]))
+		if err != nil {
+			return nil, err
+		}
+		return groupStep{
+			Group:            stringValue(m["label"]),
+			stepDependencies: dependencies,
+			Steps:            steps,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%s: unknown kind of step %s", path, kind)
+}
+
+func renderCommandStep(m map[string]interface{}, dependencies stepDependencies) commandStep {
+	step := commandStep{
+		Label:            stringValue(m["label"]),
+		stepDependencies: dependencies,
+		Agents:           stringValues(m["agents"]),
+		Env:              stringValues(m["env"]),
+		Parallelism:      intValue(m["parallelism"]),
+		TimeoutInMinutes: intValue(m["timeout_in_minutes"]),
+		ArtifactPaths:    stringList(m["artifact_paths"]),
+		Concurrency:      intValue(m["concurrency"]),
+		ConcurrencyGroup: stringValue(m["concurrency_group"]),
+	}
+
+	// a single command is written the way people write it by hand
+	if commands := stringList(m["commands"]); len(commands) == 1 {
+		step.Command = commands[0]
+	} else if len(commands) > 1 {
+		step.Command = commands
+	}
+
+	for _, pluginI := range listValue(m["plugin"]) {
+		plugin := stringMap(pluginI)
+		name := stringValue(plugin["name"])
+		configuration := stringValue(plugin["configuration"])
+		if configuration == "" {
+			step.Plugins = append(step.Plugins, name)
+			continue
+		}
+		var value interface{}
+		// the configuration has been validated as JSON already
+		_ = json.Unmarshal([]byte(configuration), &value)
+		step.Plugins = append(step.Plugins, map[string]interface{}{name: value})
+	}
+
+	if retries := listValue(m["retry"]); len(retries) > 0 {
+		retry := stringMap(retries[0])
+		step.Retry = &commandRetry{}
+
+		var automatic []automaticRetry
+		for _, automaticI := range listValue(retry["automatic"]) {
+			a := stringMap(automaticI)
+			automatic = append(automatic, automaticRetry{
+				ExitStatus:   exitStatus(stringValue(a["exit_status"])),
+				Limit:        intValue(a["limit"]),
+				SignalReason: stringValue(a["signal_reason"]),
+			})
+		}
+		// an empty automatic block retries any failure with the default limit
+		if len(automatic) == 1 && automatic[0] == (automaticRetry{}) {
+			step.Retry.Automatic = true
+		} else if len(automatic) > 0 {
+			step.Retry.Automatic = automatic
+		}
+
+		if manuals := listValue(retry["manual"]); len(manuals) > 0 {
+			manual := stringMap(manuals[0])
+			r := manualRetry{
+				PermitOnPassed: manual["permit_on_passed"] == true,
+				Reason:         stringValue(manual["reason"]),
+			}
+			if manual["allowed"] == false {
+				allowed := false
+				r.Allowed = &allowed
+			}
+			step.Retry.Manual = r
+		}
+	}
+
+	for _, softFailI := range listValue(m["soft_fail"]) {
+		step.SoftFail = append(step.SoftFail, softFail{
+			ExitStatus: exitStatus(stringValue(stringMap(softFailI)["exit_status"])),
+		})
+	}
+
+	return step
+}
+
+func renderInputField(path string, m map[string]interface{}) (inputField, error) {
+	field := inputField{
+		Text:     stringValue(m["text"]),
+		Select:   stringValue(m["select"]),
+		Key:      stringValue(m["key"]),
+		Hint:     stringValue(m["hint"]),
+		Default:  stringValue(m["default"]),
+		Multiple: m["multiple"] == true,
+	}
+	if (field.Text == "") == (field.Select == "") {
+		return field, fmt.Errorf("%s: a field needs exactly one of text or select", path)
+	}
+	if m["required"] == false {
+		required := false
+		field.Required = &required
+	}
+	for _, optionI := range listValue(m["option"]) {
+		option := stringMap(optionI)
+		field.Options = append(field.Options, inputFieldOption{
+			Label: stringValue(option["label"]),
+			Value: stringValue(option["value"]),
+		})
+	}
+	return field, nil
+}
+
+func renderStepDependencies(m map[string]interface{}) stepDependencies {
+	return stepDependencies{
+		Key:                    stringValue(m["key"]),
+		DependsOn:              stringList(m["depends_on"]),
+		AllowDependencyFailure: m["allow_dependency_failure"] == true,
+		If:                     stringValue(m["if"]),
+		Branches:               stringValue(m["branches"]),
+	}
+}
+
+func (dependencies stepDependencies) empty() bool {
+	return dependencies.Key == "" && len(dependencies.DependsOn) == 0 && !dependencies.AllowDependencyFailure &&
+		dependencies.If == "" && dependencies.Branches == ""
+}
+
+// exitStatus returns exit statuses as numbers, only "*" for any exit status is a string
+func exitStatus(value string) interface{} {
+	if status, err := strconv.Atoi(value); err == nil {
+		return status
+	}
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// stringMap returns the attributes of a block, blocks without any attributes set are nil
+func stringMap(i interface{}) map[string]interface{} {
+	m, _ := i.(map[string]interface{})
+	return m
+}
+
+func listValue(i interface{}) []interface{} {
+	l, _ := i.([]interface{})
+	return l
+}
+
+func stringValue(i interface{}) string {
+	s, _ := i.(string)
+	return s
+}
+
+// intValue returns whole numbers, states read from JSON hold them as float64
+func intValue(i interface{}) int {
+	switch n := i.(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+func stringList(i interface{}) []string {
+	var result []string
+	for _, v := range listValue(i) {
+		result = append(result, stringValue(v))
+	}
+	return result
+}
+
+func stringValues(i interface{}) map[string]string {
+	m := stringMap(i)
+	if len(m) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = stringValue(v)
+	}
+	return result
+}
@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestRenderPipelineSteps(t *testing.T) {
+	cases := []struct {
+		name          string
+		steps         []interface{}
+		configuration string
+		err           string
+	}{
+		{
+			name: "command",
+			steps: []interface{}{
+				map[string]interface{}{"command": []interface{}{map[string]interface{}{
+					"label":      "Tests",
+					"key":        "tests",
+					"commands":   []interface{}{"make test"},
+					"agents":     map[string]interface{}{"queue": "default"},
+					"depends_on": []interface{}{"build"},
+					"plugin": []interface{}{
+						map[string]interface{}{"name": "docker#v5.9.0", "configuration": `{"image":"golang"}`},
+						map[string]interface{}{"name": "test-collector#v1.0.0"},
+					},
+					"retry": []interface{}{map[string]interface{}{
+						"automatic": []interface{}{map[string]interface{}{"exit_status": "-1", "limit": 2}},
+						"manual":    []interface{}{map[string]interface{}{"allowed": false, "reason": "Flaky"}},
+					}},
+					"soft_fail": []interface{}{map[string]interface{}{"exit_status": "*"}},
+				}}},
+			},
+			configuration: `steps:
+  - label: Tests
+    command: make test
+    key: tests
+    depends_on:
+      - build
+    plugins:
+      - docker#v5.9.0:
+          image: golang
+      - test-collector#v1.0.0
+    agents:
+      queue: default
+    retry:
+      automatic:
+        - exit_status: -1
+          limit: 2
+      manual:
+        allowed: false
+        reason: Flaky
+    soft_fail:
+      - exit_status: '*'
+`,
+		},
+		{
+			name: "commands",
+			steps: []interface{}{
+				map[string]interface{}{"command": []interface{}{map[string]interface{}{
+					"commands": []interface{}{"make", "make test"},
+					"retry":    []interface{}{map[string]interface{}{"automatic": []interface{}{nil}}},
+				}}},
+			},
+			configuration: `steps:
+  - command:
+      - make
+      - make test
+    retry:
+      automatic: true
+`,
+		},
+		{
+			name: "wait block input trigger",
+			steps: []interface{}{
+				map[string]interface{}{"wait": []interface{}{nil}},
+				map[string]interface{}{"wait": []interface{}{map[string]interface{}{"continue_on_failure": true}}},
+				map[string]interface{}{"block": []interface{}{map[string]interface{}{
+					"label":         "Deploy?",
+					"blocked_state": "running",
+					"field": []interface{}{map[string]interface{}{
+						"key":      "region",
+						"select":   "Region",
+						"required": true,
+						"option": []interface{}{
+							map[string]interface{}{"label": "Europe", "value": "eu"},
+						},
+					}},
+				}}},
+				map[string]interface{}{"input": []interface{}{map[string]interface{}{
+					"label": "Release notes",
+					"field": []interface{}{map[string]interface{}{"key": "notes", "text": "Notes", "required": false}},
+				}}},
+				map[string]interface{}{"trigger": []interface{}{map[string]interface{}{
+					"pipeline": "deploy",
+					"async":    true,
+					"build": []interface{}{map[string]interface{}{
+						"branch": "main",
+						"env":    map[string]interface{}{"REGION": "eu"},
+					}},
+				}}},
+			},
+			configuration: `steps:
+  - wait
+  - wait: null
+    continue_on_failure: true
+  - block: Deploy?
+    fields:
+      - select: Region
+        key: region
+        options:
+          - label: Europe
+            value: eu
+    blocked_state: running
+  - input: Release notes
+    fields:
+      - text: Notes
+        key: notes
+        required: false
+  - trigger: deploy
+    async: true
+    build:
+      branch: main
+      env:
+        REGION: eu
+`,
+		},
+		{
+			name: "group",
+			steps: []interface{}{
+				map[string]interface{}{"group": []interface{}{map[string]interface{}{
+					"label": "Tests",
+					"key":   "tests",
+					"steps": []interface{}{
+						map[string]interface{}{"command": []interface{}{map[string]interface{}{"commands": []interface{}{"make test"}}}},
+					},
+				}}},
+			},
+			configuration: `steps:
+  - group: Tests
+    key: tests
+    steps:
+      - command: make test
+`,
+		},
+		{
+			name: "two kinds",
+			steps: []interface{}{
+				map[string]interface{}{
+					"command": []interface{}{map[string]interface{}{"commands": []interface{}{"make"}}},
+					"wait":    []interface{}{nil},
+				},
+			},
+			err: "steps.0: a step needs exactly one of command, wait, block, input, trigger, group, got 2",
+		},
+		{
+			name: "field without a kind",
+			steps: []interface{}{
+				map[string]interface{}{"input": []interface{}{map[string]interface{}{
+					"label": "Notes",
+					"field": []interface{}{map[string]interface{}{"key": "notes"}},
+				}}},
+			},
+			err: "steps.0.input.0.field.0: a field needs exactly one of text or select",
+		},
+	}
+
+	for _, c := range cases {
+		configuration, err := renderPipelineSteps(c.steps)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("%s: expected error %q, got %v", c.name, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", c.name, err)
+			continue
+		}
+		if configuration != c.configuration {
+			t.Errorf("%s: expected configuration\n%s\ngot\n%s", c.name, c.configuration, configuration)
+		}
+		if _, errs := validatePipelineConfiguration(configuration, "steps"); len(errs) > 0 {
+			t.Errorf("%s: rendered configuration is invalid: %v", c.name, errs)
+		}
+	}
+}
+
+func TestRenderPipelineSteps_resourceData(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, pipelineSchema, map[string]interface{}{
+		"name":       "test",
+		"repository": "git@github.com:saymedia/terraform-provider-buildkite.git",
+		"steps": []interface{}{
+			map[string]interface{}{"command": []interface{}{map[string]interface{}{"commands": []interface{}{"make"}}}},
+			map[string]interface{}{"wait": []interface{}{map[string]interface{}{}}},
+		},
+	})
+
+	configuration, err := renderPipelineSteps(d.Get("steps").([]interface{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "steps:\n  - command: make\n  - wait\n"; configuration != expected {
+		t.Errorf("expected configuration %q, got %q", expected, configuration)
+	}
+}
@@ -1,7 +1,9 @@
 package provider
 
 import (
+	"context"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"log"
 
@@ -11,14 +13,22 @@ import (
 
 func Provider() terraform.ResourceProvider {
 	log.Printf("[DEBUG] Buildkite provider version %s", version.Version)
-	return &schema.Provider{
+	p := &schema.Provider{
 		ResourcesMap: map[string]*schema.Resource{
-			"buildkite_org_member":        resourceOrgMember(),
-			"buildkite_pipeline":          resourcePipeline(),
-			"buildkite_pipeline_schedule": resourcePipelineSchedule(),
-			"buildkite_team":              resourceTeam(),
-			"buildkite_team_member":       resourceTeamMember(),
-			"buildkite_team_pipeline":     resourceTeamPipeline(),
+			"buildkite_agent_token":             resourceAgentToken(),
+			"buildkite_cluster":                 resourceCluster(),
+			"buildkite_cluster_agent_token":     resourceClusterAgentToken(),
+			"buildkite_cluster_queue":           resourceClusterQueue(),
+			"buildkite_org_member":              resourceOrgMember(),
+			"buildkite_organization_invitation": resourceOrganizationInvitation(),
+			"buildkite_pipeline":                resourcePipeline(),
+			"buildkite_pipeline_schedule":       resourcePipelineSchedule(),
+			"buildkite_pipeline_template":       resourcePipelineTemplate(),
+			"buildkite_team":                    resourceTeam(),
+			"buildkite_team_member":             resourceTeamMember(),
+			"buildkite_team_pipeline":           resourceTeamPipeline(),
+			"buildkite_test_suite":              resourceTestSuite(),
+			"buildkite_test_suite_team":         resourceTestSuiteTeam(),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -32,15 +42,101 @@ func Provider() terraform.ResourceProvider {
 				Required:    true,
 				DefaultFunc: schema.EnvDefaultFunc("BUILDKITE_API_TOKEN", nil),
 			},
+			"rest_api_url": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BUILDKITE_REST_API_URL", nil),
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+			"graphql_api_url": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BUILDKITE_GRAPHQL_API_URL", nil),
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+			"http_proxy": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BUILDKITE_HTTP_PROXY", nil),
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+			"ca_cert_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BUILDKITE_CA_CERT_FILE", nil),
+			},
+			"insecure_skip_verify": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BUILDKITE_INSECURE_SKIP_VERIFY", false),
+			},
+			"max_retries": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BUILDKITE_MAX_RETRIES", client.DefaultMaxRetries),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"skip_token_validation": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BUILDKITE_SKIP_TOKEN_VALIDATION", false),
+			},
 		},
-
-		ConfigureFunc: providerConfigure,
 	}
+	p.ConfigureFunc = providerConfigure(p)
+	for name, r := range p.ResourcesMap {
+		requireResourceScopes(name, r)
+	}
+
+	return p
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
-	orgName := d.Get("organization").(string)
-	apiToken := d.Get("api_token").(string)
+func providerConfigure(p *schema.Provider) schema.ConfigureFunc {
+	return func(d *schema.ResourceData) (interface{}, error) {
+		orgName := d.Get("organization").(string)
+		apiToken := d.Get("api_token").(string)
+
+		c, err := client.NewClient(orgName, apiToken, &client.Options{
+			RestAPIURL:         d.Get("rest_api_url").(string),
+			GraphQLAPIURL:      d.Get("graphql_api_url").(string),
+			HTTPProxy:          d.Get("http_proxy").(string),
+			CACertFile:         d.Get("ca_cert_file").(string),
+			InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+			MaxRetries:         d.Get("max_retries").(int),
+			SensitiveFields:    sensitiveAttributes(p.ResourcesMap),
+			// in-flight requests are aborted when Terraform is interrupted
+			StopContext: p.StopContext(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if !d.Get("skip_token_validation").(bool) {
+			ctx, cancel := context.WithTimeout(c.StopContext(), defaultTimeout)
+			defer cancel()
+
+			if err := validateToken(ctx, c, orgName); err != nil {
+				return nil, err
+			}
+		}
 
-	return client.NewClient(orgName, apiToken), nil
+		return c, nil
+	}
+}
+
+// sensitiveAttributes returns the names of all resource attributes marked Sensitive, so that the client keeps
+// their values out of the log
+func sensitiveAttributes(resources map[string]*schema.Resource) []string {
+	var names []string
+	for _, r := range resources {
+		for name, s := range r.Schema {
+			if s.Sensitive {
+				names = append(names, name)
+			}
+			if elem, ok := s.Elem.(*schema.Resource); ok {
+				names = append(names, sensitiveAttributes(map[string]*schema.Resource{name: elem})...)
+			}
+		}
+	}
+	return names
 }
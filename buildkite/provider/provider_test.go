@@ -1,16 +1,46 @@
 package provider
 
 import (
+	"fmt"
+	"log"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+
+	"github.com/saymedia/terraform-buildkite/buildkite/client"
+	"github.com/saymedia/terraform-buildkite/buildkite/testserver"
 )
 
 var testAccProviders map[string]terraform.ResourceProvider
 var testAccProvider *schema.Provider
 
+// testAccServer is the fake Buildkite API the acceptance tests run against when no API token is given
+var testAccServer *testserver.Server
+
+func TestMain(m *testing.M) {
+	if os.Getenv(resource.TestEnvVar) != "" && os.Getenv("BUILDKITE_API_TOKEN") == "" {
+		testAccServer = testserver.New("tf-acc")
+		log.Printf("[INFO] BUILDKITE_API_TOKEN is not set, running acceptance tests against %s", testAccServer.URL)
+
+		os.Setenv("BUILDKITE_ORGANIZATION", testAccServer.OrgSlug)
+		os.Setenv("BUILDKITE_API_TOKEN", testserver.APIToken)
+		os.Setenv("BUILDKITE_REST_API_URL", testAccServer.RestAPIURL())
+		os.Setenv("BUILDKITE_GRAPHQL_API_URL", testAccServer.GraphQLAPIURL())
+	}
+
+	code := m.Run()
+
+	if testAccServer != nil {
+		testAccServer.Close()
+	}
+	os.Exit(code)
+}
+
 func init() {
 	testAccProvider = Provider().(*schema.Provider)
 	testAccProviders = map[string]terraform.ResourceProvider{
@@ -28,6 +58,113 @@ func TestProvider_impl(t *testing.T) {
 	var _ terraform.ResourceProvider = Provider()
 }
 
+func TestProvider_resourceScopes(t *testing.T) {
+	for name := range Provider().(*schema.Provider).ResourcesMap {
+		if _, ok := resourceScopes[name]; !ok {
+			t.Errorf("the API token scopes needed by %s are unknown, add them to resourceScopes", name)
+		}
+	}
+}
+
+func TestProvider_configureValidatesToken(t *testing.T) {
+	server := testserver.New("tf-configure")
+	defer server.Close()
+
+	configure := func(orgSlug string, apiToken string) error {
+		return Provider().Configure(terraform.NewResourceConfigRaw(map[string]interface{}{
+			"organization":    orgSlug,
+			"api_token":       apiToken,
+			"rest_api_url":    server.RestAPIURL(),
+			"graphql_api_url": server.GraphQLAPIURL(),
+			"max_retries":     0,
+		}))
+	}
+
+	if err := configure(server.OrgSlug, testserver.APIToken); err != nil {
+		t.Fatalf("expected the token to be valid, got %v", err)
+	}
+
+	if err := configure(server.OrgSlug, "revoked"); err == nil || !strings.Contains(err.Error(), "invalid or has been revoked") {
+		t.Errorf("expected an invalid token error, got %v", err)
+	}
+
+	if err := configure("unknown", testserver.APIToken); err == nil || !strings.Contains(err.Error(), `organization "unknown" doesn't exist`) {
+		t.Errorf("expected an unknown organization error, got %v", err)
+	}
+
+	// scopes only some resources need don't fail the configuration
+	server.Scopes = []string{client.ScopeGraphQL}
+	if err := configure(server.OrgSlug, testserver.APIToken); err != nil {
+		t.Errorf("expected a token without the scopes of some resources to be valid, got %v", err)
+	}
+
+	server.Scopes = []string{client.ScopeReadPipelines, client.ScopeWritePipelines}
+	err := configure(server.OrgSlug, testserver.APIToken)
+	if err == nil || !strings.Contains(err.Error(), "missing the graphql scopes") {
+		t.Errorf("expected a missing scope error, got %v", err)
+	}
+}
+
+func TestAccProvider_missingResourceScopes(t *testing.T) {
+	if testAccServer == nil {
+		t.Skip("the scopes of the API token can only be changed on the fake API")
+	}
+	scopes := testAccServer.Scopes
+	defer func() { testAccServer.Scopes = scopes }()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				// the scopes of the token are known, the pipeline isn't created at all
+				PreConfig: func() {
+					testAccServer.Scopes = []string{client.ScopeGraphQL, client.ScopeReadPipelines}
+				},
+				Config:      testAccProvider_pipeline(false),
+				ExpectError: regexp.MustCompile("buildkite_pipeline needs the write_pipelines scopes, which the Buildkite API token lacks"),
+			},
+			resource.TestStep{
+				// the scopes of the token are unknown, the 403 of the API is explained
+				Config:      testAccProvider_pipeline(true),
+				ExpectError: regexp.MustCompile("(?s)status 403.*buildkite_pipeline needs the read_pipelines, write_pipelines, graphql scopes, check that the Buildkite API token has been granted them"),
+			},
+		},
+	})
+}
+
+func testAccProvider_pipeline(skipTokenValidation bool) string {
+	return fmt.Sprintf(`
+provider "buildkite" {
+  skip_token_validation = %t
+}
+
+resource "buildkite_pipeline" "test" {
+  name       = "tf-acc-missing-scopes"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+}
+`, skipTokenValidation)
+}
+
+func TestMissingScopesWarning(t *testing.T) {
+	token := &client.AccessToken{Scopes: []string{client.ScopeGraphQL, client.ScopeReadPipelines}}
+	warning := missingScopesWarning(token)
+	if !strings.Contains(warning, "\n  write_pipelines, needed by buildkite_pipeline") {
+		t.Errorf("expected the warning to name the resources needing write_pipelines, got %q", warning)
+	}
+	if !strings.Contains(warning, "\n  write_clusters, needed by buildkite_cluster, buildkite_cluster_agent_token, buildkite_cluster_queue") {
+		t.Errorf("expected the warning to name the resources needing write_clusters, got %q", warning)
+	}
+
+	token.Scopes = []string{}
+	for _, scopes := range resourceScopes {
+		token.Scopes = append(token.Scopes, scopes...)
+	}
+	if warning := missingScopesWarning(token); warning != "" {
+		t.Errorf("expected no warning for a token with all scopes, got %q", warning)
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
 	if v := os.Getenv("BUILDKITE_ORGANIZATION"); v == "" {
 		t.Fatal("BUILDKITE_ORGANIZATION must be set for acceptance tests")
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	"github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+// agentTokenRevokeReason is recorded in the audit log of the organization when a token is destroyed
+const agentTokenRevokeReason = "Revoked by Terraform"
+
+func resourceAgentToken() *schema.Resource {
+	return &schema.Resource{
+		Create: withContext(schema.TimeoutCreate, CreateAgentToken),
+		Read:   withContext(schema.TimeoutRead, ReadAgentToken),
+		Delete: withContext(schema.TimeoutDelete, DeleteAgentToken),
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Timeouts: resourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"token": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func CreateAgentToken(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] CreateAgentToken")
+
+	buildkiteClient := meta.(*client.Client)
+
+	res, err := buildkiteClient.CreateAgentToken(ctx, &client.AgentToken{
+		Description: d.Get("description").(string),
+	})
+	if err != nil {
+		return err
+	}
+
+	// the token is only revealed once, so it is kept in the state from now on
+	d.Set("token", res.Token)
+
+	return updateAgentTokenFromAPI(d, res)
+}
+
+func ReadAgentToken(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] ReadAgentToken")
+
+	buildkiteClient := meta.(*client.Client)
+
+	agentToken, err := buildkiteClient.GetAgentToken(ctx, d.Id())
+	if err != nil {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	return updateAgentTokenFromAPI(d, agentToken)
+}
+
+func DeleteAgentToken(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] DeleteAgentToken")
+
+	buildkiteClient := meta.(*client.Client)
+
+	return buildkiteClient.RevokeAgentToken(ctx, d.Id(), agentTokenRevokeReason)
+}
+
+func updateAgentTokenFromAPI(d *schema.ResourceData, t *client.AgentToken) error {
+	d.SetId(t.Id)
+	log.Printf("[INFO] buildkite: agent token ID: %s", d.Id())
+
+	d.Set("uuid", t.UUID)
+	d.Set("description", t.Description)
+
+	return nil
+}
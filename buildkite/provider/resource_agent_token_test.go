@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+
+	buildkiteClient "github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+func TestAccAgentToken_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteAgentTokenDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccAgentToken_basic("tf-acc-agent-token"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkiteAgentTokenExists("buildkite_agent_token.test"),
+					resource.TestCheckResourceAttr("buildkite_agent_token.test", "description", "tf-acc-agent-token"),
+					resource.TestCheckResourceAttrSet("buildkite_agent_token.test", "uuid"),
+					resource.TestCheckResourceAttrSet("buildkite_agent_token.test", "token"),
+				),
+			},
+			resource.TestStep{
+				// changing the description replaces the token
+				Config: testAccAgentToken_basic("tf-acc-agent-token-renamed"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_agent_token.test", "description", "tf-acc-agent-token-renamed"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      "buildkite_agent_token.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// the API only reveals the token when it is created
+				ImportStateVerifyIgnore: []string{"token"},
+			},
+		},
+	})
+}
+
+func testAccCheckBuildkiteAgentTokenExists(id string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found: %s", id)
+		}
+
+		_, err := client.GetAgentToken(context.Background(), rs.Primary.ID)
+		return err
+	}
+}
+
+func testAccCheckBuildkiteAgentTokenDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "buildkite_agent_token" {
+			continue
+		}
+
+		_, err := client.GetAgentToken(context.Background(), rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Agent token has not been revoked")
+		}
+
+		var notFound *buildkiteClient.NotFound
+		if !errors.As(err, &notFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testAccAgentToken_basic(description string) string {
+	return fmt.Sprintf(`
+resource "buildkite_agent_token" "test" {
+  description = "%s"
+}
+`, description)
+}
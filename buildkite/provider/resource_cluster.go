@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	"github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+func resourceCluster() *schema.Resource {
+	return &schema.Resource{
+		Create: withContext(schema.TimeoutCreate, CreateCluster),
+		Read:   withContext(schema.TimeoutRead, ReadCluster),
+		Update: withContext(schema.TimeoutUpdate, UpdateCluster),
+		Delete: withContext(schema.TimeoutDelete, DeleteCluster),
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Timeouts: resourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"emoji": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"color": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"graphql_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"default_queue_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"web_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateCluster(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] CreateCluster")
+
+	buildkiteClient := meta.(*client.Client)
+
+	res, err := buildkiteClient.CreateCluster(ctx, prepareClusterRequestPayload(d))
+	if err != nil {
+		return err
+	}
+
+	return updateClusterFromAPI(d, res)
+}
+
+func ReadCluster(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] ReadCluster")
+
+	buildkiteClient := meta.(*client.Client)
+
+	cluster, err := buildkiteClient.GetCluster(ctx, d.Id())
+	if err != nil {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	return updateClusterFromAPI(d, cluster)
+}
+
+func UpdateCluster(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] UpdateCluster")
+
+	buildkiteClient := meta.(*client.Client)
+
+	cluster := prepareClusterRequestPayload(d)
+	cluster.Id = d.Id()
+
+	res, err := buildkiteClient.UpdateCluster(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	return updateClusterFromAPI(d, res)
+}
+
+func DeleteCluster(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] DeleteCluster")
+
+	buildkiteClient := meta.(*client.Client)
+
+	return buildkiteClient.DeleteCluster(ctx, d.Id())
+}
+
+func updateClusterFromAPI(d *schema.ResourceData, c *client.Cluster) error {
+	d.SetId(c.Id)
+	log.Printf("[INFO] buildkite: cluster ID: %s", d.Id())
+
+	d.Set("uuid", c.Id)
+	d.Set("graphql_id", c.GraphQLId)
+	d.Set("default_queue_id", c.DefaultQueueId)
+	d.Set("name", c.Name)
+	d.Set("description", c.Description)
+	d.Set("emoji", c.Emoji)
+	d.Set("color", c.Color)
+	d.Set("web_url", c.WebURL)
+	d.Set("created_at", c.CreatedAt)
+
+	return nil
+}
+
+func prepareClusterRequestPayload(d *schema.ResourceData) *client.Cluster {
+	return &client.Cluster{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		Emoji:       d.Get("emoji").(string),
+		Color:       d.Get("color").(string),
+	}
+}
@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+
+	"github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+func resourceClusterAgentToken() *schema.Resource {
+	return &schema.Resource{
+		Create: withContext(schema.TimeoutCreate, CreateClusterAgentToken),
+		Read:   withContext(schema.TimeoutRead, ReadClusterAgentToken),
+		Update: withContext(schema.TimeoutUpdate, UpdateClusterAgentToken),
+		Delete: withContext(schema.TimeoutDelete, DeleteClusterAgentToken),
+		Importer: &schema.ResourceImporter{
+			State: importClusterObject,
+		},
+		Timeouts: resourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"cluster_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"allowed_ip_addresses": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.IsCIDR,
+				},
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"graphql_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"token": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateClusterAgentToken(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] CreateClusterAgentToken")
+
+	buildkiteClient := meta.(*client.Client)
+
+	res, err := buildkiteClient.CreateClusterAgentToken(ctx, prepareClusterAgentTokenRequestPayload(d))
+	if err != nil {
+		return err
+	}
+
+	// the token is only revealed once, so it is kept in the state from now on
+	d.Set("token", res.Token)
+
+	return updateClusterAgentTokenFromAPI(d, res)
+}
+
+func ReadClusterAgentToken(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] ReadClusterAgentToken")
+
+	buildkiteClient := meta.(*client.Client)
+
+	token, err := buildkiteClient.GetClusterAgentToken(ctx, d.Get("cluster_id").(string), d.Id())
+	if err != nil {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	return updateClusterAgentTokenFromAPI(d, token)
+}
+
+func UpdateClusterAgentToken(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] UpdateClusterAgentToken")
+
+	buildkiteClient := meta.(*client.Client)
+
+	token := prepareClusterAgentTokenRequestPayload(d)
+	token.Id = d.Id()
+
+	res, err := buildkiteClient.UpdateClusterAgentToken(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	return updateClusterAgentTokenFromAPI(d, res)
+}
+
+func DeleteClusterAgentToken(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] DeleteClusterAgentToken")
+
+	buildkiteClient := meta.(*client.Client)
+
+	return buildkiteClient.RevokeClusterAgentToken(ctx, d.Get("cluster_id").(string), d.Id())
+}
+
+func updateClusterAgentTokenFromAPI(d *schema.ResourceData, t *client.ClusterAgentToken) error {
+	d.SetId(t.Id)
+	log.Printf("[INFO] buildkite: cluster agent token ID: %s", d.Id())
+
+	d.Set("uuid", t.Id)
+	d.Set("graphql_id", t.GraphQLId)
+	d.Set("cluster_id", t.ClusterId)
+	d.Set("description", t.Description)
+	d.Set("allowed_ip_addresses", t.AllowedIPAddresses)
+	d.Set("created_at", t.CreatedAt)
+
+	return nil
+}
+
+func prepareClusterAgentTokenRequestPayload(d *schema.ResourceData) *client.ClusterAgentToken {
+	token := &client.ClusterAgentToken{
+		ClusterId:   d.Get("cluster_id").(string),
+		Description: d.Get("description").(string),
+	}
+	for _, cidr := range d.Get("allowed_ip_addresses").(*schema.Set).List() {
+		token.AllowedIPAddresses = append(token.AllowedIPAddresses, cidr.(string))
+	}
+
+	return token
+}
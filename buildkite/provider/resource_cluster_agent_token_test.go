@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+
+	buildkiteClient "github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+func TestAccClusterAgentToken_basic(t *testing.T) {
+	var tokenID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteClusterAgentTokenDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccClusterAgentToken_basic("Elastic CI Stack", `"10.0.0.0/8"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkiteClusterAgentTokenExists("buildkite_cluster_agent_token.test", &tokenID),
+					resource.TestCheckResourceAttr("buildkite_cluster_agent_token.test", "description", "Elastic CI Stack"),
+					resource.TestCheckResourceAttr("buildkite_cluster_agent_token.test", "allowed_ip_addresses.#", "1"),
+					resource.TestCheckResourceAttrSet("buildkite_cluster_agent_token.test", "token"),
+					resource.TestCheckResourceAttrPair("buildkite_cluster_agent_token.test", "cluster_id", "buildkite_cluster.test", "uuid"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccClusterAgentToken_basic("Elastic CI Stack (production)", `"10.0.0.0/8", "192.168.0.0/16"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_cluster_agent_token.test", "description", "Elastic CI Stack (production)"),
+					resource.TestCheckResourceAttr("buildkite_cluster_agent_token.test", "allowed_ip_addresses.#", "2"),
+					resource.TestCheckResourceAttrSet("buildkite_cluster_agent_token.test", "token"),
+					// the description and IP addresses are updated in place, replacing the token would break agents
+					resource.TestCheckResourceAttrPtr("buildkite_cluster_agent_token.test", "id", &tokenID),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      "buildkite_cluster_agent_token.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs := s.RootModule().Resources["buildkite_cluster_agent_token.test"]
+					return rs.Primary.Attributes["cluster_id"] + "/" + rs.Primary.ID, nil
+				},
+				// the API only reveals the token when it is created
+				ImportStateVerifyIgnore: []string{"token"},
+			},
+		},
+	})
+}
+
+func TestAccClusterAgentToken_invalidCIDR(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccClusterAgentToken_basic("Elastic CI Stack", `"10.0.0.1"`),
+				ExpectError: regexp.MustCompile(`invalid CIDR address: 10.0.0.1`),
+			},
+		},
+	})
+}
+
+func testAccCheckBuildkiteClusterAgentTokenExists(id string, tokenID *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found: %s", id)
+		}
+
+		if _, err := client.GetClusterAgentToken(context.Background(), rs.Primary.Attributes["cluster_id"], rs.Primary.ID); err != nil {
+			return err
+		}
+
+		*tokenID = rs.Primary.ID
+		return nil
+	}
+}
+
+func testAccCheckBuildkiteClusterAgentTokenDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "buildkite_cluster_agent_token" {
+			continue
+		}
+
+		_, err := client.GetClusterAgentToken(context.Background(), rs.Primary.Attributes["cluster_id"], rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Cluster agent token has not been revoked")
+		}
+
+		var notFound *buildkiteClient.NotFound
+		if !errors.As(err, &notFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testAccClusterAgentToken_basic(description string, allowedIPAddresses string) string {
+	return fmt.Sprintf(`
+resource "buildkite_cluster" "test" {
+  name = "tf-acc-cluster-agent-token"
+}
+
+resource "buildkite_cluster_agent_token" "test" {
+  cluster_id           = buildkite_cluster.test.uuid
+  description          = "%s"
+  allowed_ip_addresses = [%s]
+}
+`, description, allowedIPAddresses)
+}
@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+
+	"github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+var clusterQueueKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func resourceClusterQueue() *schema.Resource {
+	return &schema.Resource{
+		Create: withContext(schema.TimeoutCreate, CreateClusterQueue),
+		Read:   withContext(schema.TimeoutRead, ReadClusterQueue),
+		Update: withContext(schema.TimeoutUpdate, UpdateClusterQueue),
+		Delete: withContext(schema.TimeoutDelete, DeleteClusterQueue),
+		Importer: &schema.ResourceImporter{
+			State: importClusterObject,
+		},
+		Timeouts:      resourceTimeouts(),
+		CustomizeDiff: customizeClusterQueueDiff,
+
+		Schema: map[string]*schema.Schema{
+			"cluster_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"key": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringMatch(clusterQueueKeyPattern,
+					"may only contain letters, numbers, hyphens and underscores"),
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"dispatch_paused": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"hosted_agents": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"instance_shape": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"agent_image_ref": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"graphql_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"web_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateClusterQueue(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] CreateClusterQueue")
+
+	buildkiteClient := meta.(*client.Client)
+
+	res, err := buildkiteClient.CreateClusterQueue(ctx, prepareClusterQueueRequestPayload(d))
+	if err != nil {
+		return err
+	}
+
+	return updateClusterQueueFromAPI(d, res)
+}
+
+func ReadClusterQueue(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] ReadClusterQueue")
+
+	buildkiteClient := meta.(*client.Client)
+
+	queue, err := buildkiteClient.GetClusterQueue(ctx, d.Get("cluster_id").(string), d.Id())
+	if err != nil {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	return updateClusterQueueFromAPI(d, queue)
+}
+
+func UpdateClusterQueue(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] UpdateClusterQueue")
+
+	buildkiteClient := meta.(*client.Client)
+
+	queue := prepareClusterQueueRequestPayload(d)
+	queue.Id = d.Id()
+
+	res, err := buildkiteClient.UpdateClusterQueue(ctx, queue)
+	if err != nil {
+		return err
+	}
+
+	return updateClusterQueueFromAPI(d, res)
+}
+
+func DeleteClusterQueue(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] DeleteClusterQueue")
+
+	buildkiteClient := meta.(*client.Client)
+
+	return buildkiteClient.DeleteClusterQueue(ctx, d.Get("cluster_id").(string), d.Id())
+}
+
+// importClusterObject imports queues and agent tokens by <cluster uuid>/<uuid>, since they are only addressable
+// within their cluster
+func importClusterObject(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(d.Id(), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("expected an id of the form <cluster uuid>/<uuid>, got %q", d.Id())
+	}
+
+	d.Set("cluster_id", parts[0])
+	d.SetId(parts[1])
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// customizeClusterQueueDiff replaces the queue when it switches between hosted and self-hosted agents, which
+// Buildkite doesn't allow for an existing queue
+func customizeClusterQueueDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.HasChange("hosted_agents") {
+		return nil
+	}
+
+	old, new := d.GetChange("hosted_agents")
+	if len(old.([]interface{})) != len(new.([]interface{})) {
+		return d.ForceNew("hosted_agents")
+	}
+	return nil
+}
+
+func updateClusterQueueFromAPI(d *schema.ResourceData, q *client.ClusterQueue) error {
+	d.SetId(q.Id)
+	log.Printf("[INFO] buildkite: cluster queue ID: %s", d.Id())
+
+	d.Set("uuid", q.Id)
+	d.Set("graphql_id", q.GraphQLId)
+	d.Set("cluster_id", q.ClusterId)
+	d.Set("key", q.Key)
+	d.Set("description", q.Description)
+	d.Set("dispatch_paused", q.DispatchPaused)
+	d.Set("web_url", q.WebURL)
+	d.Set("created_at", q.CreatedAt)
+
+	var hostedAgents []interface{}
+	if q.HostedAgents != nil {
+		hostedAgents = append(hostedAgents, map[string]interface{}{
+			"instance_shape":  q.HostedAgents.InstanceShape,
+			"agent_image_ref": q.HostedAgents.AgentImageRef,
+		})
+	}
+	d.Set("hosted_agents", hostedAgents)
+
+	return nil
+}
+
+func prepareClusterQueueRequestPayload(d *schema.ResourceData) *client.ClusterQueue {
+	queue := &client.ClusterQueue{
+		ClusterId:      d.Get("cluster_id").(string),
+		Key:            d.Get("key").(string),
+		Description:    d.Get("description").(string),
+		DispatchPaused: d.Get("dispatch_paused").(bool),
+	}
+
+	if hostedAgents := d.Get("hosted_agents").([]interface{}); len(hostedAgents) > 0 && hostedAgents[0] != nil {
+		settings := hostedAgents[0].(map[string]interface{})
+		queue.HostedAgents = &client.HostedAgents{
+			InstanceShape: settings["instance_shape"].(string),
+			AgentImageRef: settings["agent_image_ref"].(string),
+		}
+	}
+
+	return queue
+}
@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+
+	buildkiteClient "github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+func TestAccClusterQueue_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteClusterQueueDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccClusterQueue_basic("Deployments", false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkiteClusterQueueExists("buildkite_cluster_queue.test"),
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.test", "key", "tf-acc-deploy"),
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.test", "description", "Deployments"),
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.test", "dispatch_paused", "false"),
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.test", "hosted_agents.#", "0"),
+					resource.TestCheckResourceAttrPair("buildkite_cluster_queue.test", "cluster_id", "buildkite_cluster.test", "uuid"),
+					resource.TestCheckResourceAttrSet("buildkite_cluster_queue.test", "graphql_id"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccClusterQueue_basic("Paused deployments", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.test", "description", "Paused deployments"),
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.test", "dispatch_paused", "true"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      "buildkite_cluster_queue.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs := s.RootModule().Resources["buildkite_cluster_queue.test"]
+					return rs.Primary.Attributes["cluster_id"] + "/" + rs.Primary.ID, nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccClusterQueue_createPaused(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteClusterQueueDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				// the queue is paused by a request to the queue of its cluster once it is created
+				Config: testAccClusterQueue_basic("Deployments", true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkiteClusterQueueExists("buildkite_cluster_queue.test"),
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.test", "dispatch_paused", "true"),
+					resource.TestCheckResourceAttrPair("buildkite_cluster_queue.test", "cluster_id", "buildkite_cluster.test", "uuid"),
+				),
+			},
+			resource.TestStep{
+				Config:   testAccClusterQueue_basic("Deployments", true),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccClusterQueue_hostedAgents(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteClusterQueueDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccClusterQueue_hostedAgents("LINUX_AMD64_2X4"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.test", "hosted_agents.#", "1"),
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.test", "hosted_agents.0.instance_shape", "LINUX_AMD64_2X4"),
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.test", "hosted_agents.0.agent_image_ref", "buildkite/agent:3"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccClusterQueue_hostedAgents("LINUX_AMD64_4X16"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.test", "hosted_agents.0.instance_shape", "LINUX_AMD64_4X16"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBuildkiteClusterQueueExists(id string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found: %s", id)
+		}
+
+		_, err := client.GetClusterQueue(context.Background(), rs.Primary.Attributes["cluster_id"], rs.Primary.ID)
+		return err
+	}
+}
+
+func testAccCheckBuildkiteClusterQueueDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "buildkite_cluster_queue" {
+			continue
+		}
+
+		_, err := client.GetClusterQueue(context.Background(), rs.Primary.Attributes["cluster_id"], rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Cluster queue still exists")
+		}
+
+		var notFound *buildkiteClient.NotFound
+		if !errors.As(err, &notFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testAccClusterQueue_basic(description string, dispatchPaused bool) string {
+	return fmt.Sprintf(`
+resource "buildkite_cluster" "test" {
+  name = "tf-acc-cluster-queue"
+}
+
+resource "buildkite_cluster_queue" "test" {
+  cluster_id      = buildkite_cluster.test.uuid
+  key             = "tf-acc-deploy"
+  description     = "%s"
+  dispatch_paused = %t
+}
+`, description, dispatchPaused)
+}
+
+func testAccClusterQueue_hostedAgents(instanceShape string) string {
+	return fmt.Sprintf(`
+resource "buildkite_cluster" "test" {
+  name = "tf-acc-cluster-hosted-queue"
+}
+
+resource "buildkite_cluster_queue" "test" {
+  cluster_id = buildkite_cluster.test.uuid
+  key        = "tf-acc-hosted"
+
+  hosted_agents {
+    instance_shape  = "%s"
+    agent_image_ref = "buildkite/agent:3"
+  }
+}
+`, instanceShape)
+}
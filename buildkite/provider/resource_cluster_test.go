@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+
+	buildkiteClient "github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+func TestAccCluster_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteClusterDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccCluster_basic("Default cluster", "#FF0000"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkiteClusterExists("buildkite_cluster.test"),
+					resource.TestCheckResourceAttr("buildkite_cluster.test", "name", "tf-acc-cluster"),
+					resource.TestCheckResourceAttr("buildkite_cluster.test", "description", "Default cluster"),
+					resource.TestCheckResourceAttr("buildkite_cluster.test", "emoji", ":terraform:"),
+					resource.TestCheckResourceAttr("buildkite_cluster.test", "color", "#FF0000"),
+					resource.TestCheckResourceAttrPair("buildkite_cluster.test", "uuid", "buildkite_cluster.test", "id"),
+					resource.TestCheckResourceAttrSet("buildkite_cluster.test", "graphql_id"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccCluster_basic("Updated cluster", "#00FF00"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_cluster.test", "description", "Updated cluster"),
+					resource.TestCheckResourceAttr("buildkite_cluster.test", "color", "#00FF00"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      "buildkite_cluster.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckBuildkiteClusterExists(id string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found: %s", id)
+		}
+
+		_, err := client.GetCluster(context.Background(), rs.Primary.ID)
+		return err
+	}
+}
+
+func testAccCheckBuildkiteClusterDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "buildkite_cluster" {
+			continue
+		}
+
+		_, err := client.GetCluster(context.Background(), rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Cluster still exists")
+		}
+
+		var notFound *buildkiteClient.NotFound
+		if !errors.As(err, &notFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testAccCluster_basic(description string, color string) string {
+	return fmt.Sprintf(`
+resource "buildkite_cluster" "test" {
+  name        = "tf-acc-cluster"
+  description = "%s"
+  emoji       = ":terraform:"
+  color       = "%s"
+}
+`, description, color)
+}
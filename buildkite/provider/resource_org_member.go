@@ -1,9 +1,12 @@
 package provider
 
 import (
+	"context"
+	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/pkg/errors"
 	"log"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 
@@ -16,13 +19,14 @@ var (
 
 func resourceOrgMember() *schema.Resource {
 	return &schema.Resource{
-		Create: CreateOrganizationMember,
-		Read:   ReadOrganizationMember,
-		Update: UpdateOrganizationMember,
-		Delete: DeleteOrganizationMember,
+		Create: withContext(schema.TimeoutCreate, CreateOrganizationMember),
+		Read:   withContext(schema.TimeoutRead, ReadOrganizationMember),
+		Update: withContext(schema.TimeoutUpdate, UpdateOrganizationMember),
+		Delete: withContext(schema.TimeoutDelete, DeleteOrganizationMember),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: importOrgMember,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"uuid": {
@@ -58,20 +62,21 @@ func resourceOrgMember() *schema.Resource {
 	}
 }
 
-func CreateOrganizationMember(d *schema.ResourceData, meta interface{}) error {
+func CreateOrganizationMember(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] CreateOrganizationMember")
 	return errors.New("org member cannot be created")
 }
 
-func ReadOrganizationMember(d *schema.ResourceData, meta interface{}) error {
+func ReadOrganizationMember(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] ReadOrganizationMember")
 
 	buildkiteClient := meta.(*client.Client)
 	uuid := d.Id()
 
-	orgMember, err := buildkiteClient.GetOrganizationMember(uuid)
+	orgMember, err := buildkiteClient.GetOrganizationMember(ctx, uuid)
 	if err != nil {
-		if _, ok := err.(*client.NotFound); ok {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
 			d.SetId("")
 			return nil
 		}
@@ -81,14 +86,14 @@ func ReadOrganizationMember(d *schema.ResourceData, meta interface{}) error {
 	return updateOrgMemberFromAPI(d, orgMember)
 }
 
-func UpdateOrganizationMember(d *schema.ResourceData, meta interface{}) error {
+func UpdateOrganizationMember(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] UpdateOrganizationMember")
 
 	buildkiteClient := meta.(*client.Client)
 
 	orgMember := prepareOrgMemberRequestPayload(d)
 
-	res, err := buildkiteClient.UpdateOrganizationMember(orgMember)
+	res, err := buildkiteClient.UpdateOrganizationMember(ctx, orgMember)
 	if err != nil {
 		return err
 	}
@@ -96,13 +101,63 @@ func UpdateOrganizationMember(d *schema.ResourceData, meta interface{}) error {
 	return updateOrgMemberFromAPI(d, res)
 }
 
-func DeleteOrganizationMember(d *schema.ResourceData, meta interface{}) error {
+func DeleteOrganizationMember(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] DeleteOrganizationMember")
 
 	buildkiteClient := meta.(*client.Client)
 	id := d.Get("member_id").(string)
 
-	return buildkiteClient.DeleteOrganizationMember(id)
+	return buildkiteClient.DeleteOrganizationMember(ctx, id)
+}
+
+const (
+	// importOrgMemberInvitationPrefix imports the membership of the person who accepted an invitation,
+	// e.g. invitation:<invitation id>
+	importOrgMemberInvitationPrefix = "invitation:"
+	// importOrgMemberEmailPrefix imports the membership of the user with the email address, e.g. email:alice@example.com
+	importOrgMemberEmailPrefix = "email:"
+)
+
+// importOrgMember imports a membership by its UUID, by the email address of the user or by the invitation it resulted
+// from
+func importOrgMember(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	isInvitation := strings.HasPrefix(d.Id(), importOrgMemberInvitationPrefix)
+	isEmail := strings.HasPrefix(d.Id(), importOrgMemberEmailPrefix)
+	if !isInvitation && !isEmail {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	buildkiteClient := meta.(*client.Client)
+	ctx, cancel := context.WithTimeout(buildkiteClient.StopContext(), d.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	if isEmail {
+		member, err := buildkiteClient.GetOrganizationMemberByEmail(ctx, strings.TrimPrefix(d.Id(), importOrgMemberEmailPrefix))
+		if err != nil {
+			return nil, err
+		}
+
+		d.SetId(member.UUID)
+		return []*schema.ResourceData{d}, nil
+	}
+
+	invitationId := strings.TrimPrefix(d.Id(), importOrgMemberInvitationPrefix)
+	invitation, err := buildkiteClient.GetOrganizationInvitation(ctx, invitationId)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.State != client.OrganizationInvitationStateAccepted || invitation.AcceptedBy == nil {
+		return nil, fmt.Errorf("the invitation of %s is %s, only accepted invitations have a membership to import",
+			invitation.Email, strings.ToLower(invitation.State))
+	}
+
+	member, err := buildkiteClient.GetOrganizationMemberByUserId(ctx, invitation.AcceptedBy.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	d.SetId(member.UUID)
+	return []*schema.ResourceData{d}, nil
 }
 
 func updateOrgMemberFromAPI(d *schema.ResourceData, t *client.OrganizationMember) error {
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccOrgMember_importByEmail(t *testing.T) {
+	if testAccServer == nil {
+		t.Skip("organization members need an existing user, which only the fake Buildkite API can provide")
+	}
+	memberUUID, _ := testAccServer.AddOrganizationMember("Terraform Import", "tf-acc-import@example.com", "ADMIN")
+	testAccServer.AddOrganizationMember("Terraform Twin", "tf-acc-twin@example.com", "MEMBER")
+	testAccServer.AddOrganizationMember("Terraform Twin", "tf-acc-twin@example.com", "MEMBER")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:        testAccOrgMember_import,
+				ResourceName:  "buildkite_org_member.test",
+				ImportState:   true,
+				ImportStateId: "email:tf-acc-import@example.com",
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected a single membership, got %d", len(states))
+					}
+					if states[0].ID != memberUUID {
+						return fmt.Errorf("expected membership %s, got %s", memberUUID, states[0].ID)
+					}
+					if role := states[0].Attributes["role"]; role != "ADMIN" {
+						return fmt.Errorf("expected role ADMIN, got %s", role)
+					}
+					return nil
+				},
+			},
+			resource.TestStep{
+				Config:        testAccOrgMember_import,
+				ResourceName:  "buildkite_org_member.test",
+				ImportState:   true,
+				ImportStateId: "email:tf-acc-import",
+				ExpectError:   regexp.MustCompile("no member of organization .* has the email tf-acc-import"),
+			},
+			resource.TestStep{
+				Config:        testAccOrgMember_import,
+				ResourceName:  "buildkite_org_member.test",
+				ImportState:   true,
+				ImportStateId: "email:tf-acc-twin@example.com",
+				ExpectError:   regexp.MustCompile("2 members of organization .* have the email tf-acc-twin@example.com"),
+			},
+		},
+	})
+}
+
+const testAccOrgMember_import = `
+resource "buildkite_org_member" "test" {
+  role = "ADMIN"
+}
+`
@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+
+	"github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+// invitations can't be changed once they have been sent, so all arguments replace the invitation
+func resourceOrganizationInvitation() *schema.Resource {
+	return &schema.Resource{
+		Create: withContext(schema.TimeoutCreate, CreateOrganizationInvitation),
+		Read:   withContext(schema.TimeoutRead, ReadOrganizationInvitation),
+		Delete: withContext(schema.TimeoutDelete, DeleteOrganizationInvitation),
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Timeouts: resourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"email": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"role": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      client.OrganizationMemberRoleMember,
+				ValidateFunc: validation.StringInSlice(ValidOrganizationMemberRole, false),
+			},
+			"team": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"team_id": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"role": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      client.TeamMemberRoleMember,
+							ValidateFunc: validation.StringInSlice(ValidTeamMemberRole, false),
+						},
+					},
+				},
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"accepted_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"user_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateOrganizationInvitation(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] CreateOrganizationInvitation")
+
+	buildkiteClient := meta.(*client.Client)
+
+	res, err := buildkiteClient.CreateOrganizationInvitation(ctx, prepareOrganizationInvitationRequestPayload(d))
+	if err != nil {
+		return err
+	}
+
+	return updateOrganizationInvitationFromAPI(d, res)
+}
+
+func ReadOrganizationInvitation(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] ReadOrganizationInvitation")
+
+	buildkiteClient := meta.(*client.Client)
+
+	invitation, err := buildkiteClient.GetOrganizationInvitation(ctx, d.Id())
+	if err != nil {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+	// a revoked invitation can't be accepted anymore, so it is as good as deleted
+	if invitation.State == client.OrganizationInvitationStateRevoked {
+		d.SetId("")
+		return nil
+	}
+
+	return updateOrganizationInvitationFromAPI(d, invitation)
+}
+
+func DeleteOrganizationInvitation(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] DeleteOrganizationInvitation")
+
+	buildkiteClient := meta.(*client.Client)
+
+	invitation, err := buildkiteClient.GetOrganizationInvitation(ctx, d.Id())
+	if err != nil {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		return err
+	}
+	// only pending invitations can be revoked, the membership of an accepted invitation is managed by
+	// buildkite_org_member
+	if invitation.State != client.OrganizationInvitationStatePending {
+		log.Printf("[INFO] buildkite: organization invitation %s is %s, it is only removed from the state", d.Id(), invitation.State)
+		return nil
+	}
+
+	return buildkiteClient.RevokeOrganizationInvitation(ctx, d.Id())
+}
+
+func updateOrganizationInvitationFromAPI(d *schema.ResourceData, i *client.OrganizationInvitation) error {
+	d.SetId(i.Id)
+	log.Printf("[INFO] buildkite: organization invitation ID: %s", d.Id())
+
+	d.Set("uuid", i.UUID)
+	d.Set("email", i.Email)
+	d.Set("role", i.Role)
+	d.Set("state", i.State)
+	d.Set("created_at", i.CreatedAt)
+	d.Set("accepted_at", i.AcceptedAt)
+
+	userId := ""
+	if i.AcceptedBy != nil {
+		userId = i.AcceptedBy.Id
+	}
+	d.Set("user_id", userId)
+
+	teams := make([]interface{}, len(i.Teams))
+	for index, team := range i.Teams {
+		teams[index] = map[string]interface{}{
+			"team_id": team.TeamId,
+			"role":    team.Role,
+		}
+	}
+	if err := d.Set("team", teams); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func prepareOrganizationInvitationRequestPayload(d *schema.ResourceData) *client.OrganizationInvitation {
+	req := &client.OrganizationInvitation{
+		Email: d.Get("email").(string),
+		Role:  d.Get("role").(string),
+	}
+
+	for _, teamI := range d.Get("team").(*schema.Set).List() {
+		team := teamI.(map[string]interface{})
+		req.Teams = append(req.Teams, client.InvitationTeamAccess{
+			TeamId: team["team_id"].(string),
+			Role:   team["role"].(string),
+		})
+	}
+
+	return req
+}
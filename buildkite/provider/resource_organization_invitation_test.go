@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+
+	buildkiteClient "github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+func TestAccOrganizationInvitation_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteOrganizationInvitationDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccOrganizationInvitation_basic("tf-acc-invite@example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkiteOrganizationInvitationExists("buildkite_organization_invitation.test"),
+					resource.TestCheckResourceAttr("buildkite_organization_invitation.test", "email", "tf-acc-invite@example.com"),
+					resource.TestCheckResourceAttr("buildkite_organization_invitation.test", "role", "MEMBER"),
+					resource.TestCheckResourceAttr("buildkite_organization_invitation.test", "state", "PENDING"),
+					resource.TestCheckResourceAttr("buildkite_organization_invitation.test", "team.#", "1"),
+					resource.TestCheckResourceAttr("buildkite_organization_invitation.test", "user_id", ""),
+					resource.TestCheckResourceAttrSet("buildkite_organization_invitation.test", "uuid"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      "buildkite_organization_invitation.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccOrganizationInvitation_manyTeams(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteOrganizationInvitationDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				// more teams than fit on a page of the teams of the invitation
+				Config: testAccOrganizationInvitation_manyTeams(5),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkiteOrganizationInvitationExists("buildkite_organization_invitation.test"),
+					resource.TestCheckResourceAttr("buildkite_organization_invitation.test", "team.#", "5"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      "buildkite_organization_invitation.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccOrganizationInvitation_accepted(t *testing.T) {
+	if testAccServer == nil {
+		t.Skip("invitations can only be accepted by the fake Buildkite API")
+	}
+
+	var memberUUID, userID string
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteOrganizationInvitationDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccOrganizationInvitation_basic("tf-acc-accept@example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_organization_invitation.test", "state", "PENDING"),
+				),
+			},
+			resource.TestStep{
+				PreConfig: func() {
+					var err error
+					memberUUID, userID, err = testAccServer.AcceptInvitation("tf-acc-accept@example.com", "Terraform Acceptance")
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccOrganizationInvitation_basic("tf-acc-accept@example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_organization_invitation.test", "state", "ACCEPTED"),
+					resource.TestCheckResourceAttrSet("buildkite_organization_invitation.test", "accepted_at"),
+					func(s *terraform.State) error {
+						return resource.TestCheckResourceAttr("buildkite_organization_invitation.test", "user_id", userID)(s)
+					},
+				),
+			},
+			resource.TestStep{
+				Config:       testAccOrganizationInvitation_basic("tf-acc-accept@example.com"),
+				ResourceName: "buildkite_org_member.test",
+				ImportState:  true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs, ok := s.RootModule().Resources["buildkite_organization_invitation.test"]
+					if !ok {
+						return "", fmt.Errorf("Not found: buildkite_organization_invitation.test")
+					}
+					return "invitation:" + rs.Primary.ID, nil
+				},
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected a single membership, got %d", len(states))
+					}
+					if states[0].ID != memberUUID {
+						return fmt.Errorf("expected membership %s, got %s", memberUUID, states[0].ID)
+					}
+					if email := states[0].Attributes["user_email"]; email != "tf-acc-accept@example.com" {
+						return fmt.Errorf("expected the membership of tf-acc-accept@example.com, got %s", email)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func testAccCheckBuildkiteOrganizationInvitationExists(id string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found: %s", id)
+		}
+
+		_, err := client.GetOrganizationInvitation(context.Background(), rs.Primary.ID)
+		return err
+	}
+}
+
+// invitations can't be deleted, a destroyed invitation is revoked unless it was accepted before
+func testAccCheckBuildkiteOrganizationInvitationDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "buildkite_organization_invitation" {
+			continue
+		}
+
+		invitation, err := client.GetOrganizationInvitation(context.Background(), rs.Primary.ID)
+		if err != nil {
+			var notFound *buildkiteClient.NotFound
+			if errors.As(err, &notFound) {
+				continue
+			}
+			return err
+		}
+		if invitation.State == buildkiteClient.OrganizationInvitationStatePending {
+			return fmt.Errorf("Organization invitation is still pending")
+		}
+	}
+
+	return nil
+}
+
+func testAccOrganizationInvitation_basic(email string) string {
+	return fmt.Sprintf(`
+resource "buildkite_team" "test" {
+  name = "tf-acc-team-for-invitation"
+}
+
+resource "buildkite_organization_invitation" "test" {
+  email = "%s"
+
+  team {
+    team_id = buildkite_team.test.team_id
+    role    = "MAINTAINER"
+  }
+}
+`, email)
+}
+
+func testAccOrganizationInvitation_manyTeams(teams int) string {
+	return fmt.Sprintf(`
+resource "buildkite_team" "test" {
+  count = %d
+  name  = "tf-acc-team-for-invitation-${count.index}"
+}
+
+resource "buildkite_organization_invitation" "test" {
+  email = "tf-acc-invite-teams@example.com"
+
+  dynamic "team" {
+    for_each = buildkite_team.test[*].team_id
+    content {
+      team_id = team.value
+    }
+  }
+}
+`, teams)
+}
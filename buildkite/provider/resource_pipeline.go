@@ -1,17 +1,23 @@
 package provider
 
 import (
+	"context"
 	"errors"
 	"log"
+	"sort"
 
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 
 	"github.com/saymedia/terraform-buildkite/buildkite/client"
 )
 
 var (
 	providerSettingsExcluded = []string{"repository", "account"}
-	pipelineSchema           = map[string]*schema.Schema{
+	// providerSettingsAttributes are the settings blocks of the repository providers, a pipeline has at most one
+	providerSettingsAttributes = []string{"github_settings", "github_enterprise_settings", "bitbucket_settings", "gitlab_settings"}
+	pipelineSchema             = map[string]*schema.Schema{
 		"slug": {
 			Type:     schema.TypeString,
 			Computed: true,
@@ -57,10 +63,17 @@ var (
 			Optional: true,
 			Default:  "master",
 		},
+		// changing the cluster moves the pipeline in place, removing the attribute leaves the pipeline in the cluster
+		// it is in
+		"cluster_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
 		"env": {
 			Type:          schema.TypeMap,
 			Optional:      true,
-			ConflictsWith: []string{"configuration"},
+			ConflictsWith: []string{"configuration", "steps"},
 			Elem: &schema.Schema{
 				Type: schema.TypeString,
 			},
@@ -72,22 +85,66 @@ var (
 			Type:     schema.TypeString,
 			Computed: true,
 		},
+		// the configuration of a pipeline using steps blocks or the deprecated step and env attributes is read back as
+		// the YAML they render to, it isn't computed so that removing it clears the steps of the pipeline
 		"configuration": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			ConflictsWith:    []string{"step", "env", "pipeline_template_id", "steps"},
+			DiffSuppressFunc: suppressEquivalentPipelineConfiguration,
+			ValidateFunc:     validatePipelineConfiguration,
+		},
+		// the steps of a pipeline using a template come from the template, configuration only shows the steps of
+		// the pipeline if they drifted from the template
+		"pipeline_template_id": {
 			Type:          schema.TypeString,
 			Optional:      true,
-			ConflictsWith: []string{"step", "env"},
+			ConflictsWith: []string{"configuration", "step", "steps"},
 		},
+		"steps": {
+			Type:          schema.TypeList,
+			Optional:      true,
+			ConflictsWith: []string{"configuration", "step", "env", "pipeline_template_id"},
+			Elem:          pipelineStepsSchema(false),
+		},
+		// teams given by team_ids get "MANAGE_BUILD_AND_READ" access, team blocks choose the access level
 		"team_ids": {
-			Type:     schema.TypeSet,
-			Optional: true,
+			Type:          schema.TypeSet,
+			Optional:      true,
+			ConflictsWith: []string{"team"},
 			Elem: &schema.Schema{
 				Type: schema.TypeString,
 			},
 		},
+		"team": {
+			Type:          schema.TypeSet,
+			Optional:      true,
+			ConflictsWith: []string{"team_ids"},
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"team_id": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"access_level": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      client.TeamPipelineAccessManage,
+						ValidateFunc: validation.StringInSlice(ValidTeamPipelineAccessLevels, false),
+					},
+				},
+			},
+		},
+		// pipelines using step and env are switched to the YAML equivalent of their steps, which configuration shows
+		"migrate_legacy_steps": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
 		"step": {
 			Type:          schema.TypeList,
 			Optional:      true,
-			ConflictsWith: []string{"configuration"},
+			ConflictsWith: []string{"configuration", "steps"},
 			Elem: &schema.Resource{
 				Schema: map[string]*schema.Schema{
 					"type": {
@@ -147,7 +204,7 @@ var (
 			Optional:      true,
 			Computed:      true,
 			MaxItems:      1,
-			ConflictsWith: []string{"github_settings"},
+			ConflictsWith: []string{"github_settings", "github_enterprise_settings", "gitlab_settings"},
 			Elem: &schema.Resource{
 				Schema: map[string]*schema.Schema{
 					"trigger_mode": {
@@ -202,7 +259,23 @@ var (
 			Optional:      true,
 			Computed:      true,
 			MaxItems:      1,
-			ConflictsWith: []string{"bitbucket_settings"},
+			ConflictsWith: []string{"bitbucket_settings", "github_enterprise_settings", "gitlab_settings"},
+			Elem:          githubSettingsResource(),
+		},
+		"github_enterprise_settings": {
+			Type:          schema.TypeList,
+			Optional:      true,
+			Computed:      true,
+			MaxItems:      1,
+			ConflictsWith: []string{"bitbucket_settings", "github_settings", "gitlab_settings"},
+			Elem:          githubSettingsResource(),
+		},
+		"gitlab_settings": {
+			Type:          schema.TypeList,
+			Optional:      true,
+			Computed:      true,
+			MaxItems:      1,
+			ConflictsWith: []string{"bitbucket_settings", "github_settings", "github_enterprise_settings"},
 			Elem: &schema.Resource{
 				Schema: map[string]*schema.Schema{
 					"trigger_mode": {
@@ -228,15 +301,6 @@ var (
 						Optional: true,
 						Default:  true,
 					},
-					"build_pull_request_forks": {
-						Type:     schema.TypeBool,
-						Optional: true,
-					},
-					"prefix_pull_request_fork_branch_names": {
-						Type:     schema.TypeBool,
-						Optional: true,
-						Default:  true,
-					},
 					"build_tags": {
 						Type:     schema.TypeBool,
 						Optional: true,
@@ -246,36 +310,40 @@ var (
 						Optional: true,
 						Default:  true,
 					},
-					"publish_commit_status_per_step": {
-						Type:     schema.TypeBool,
-						Optional: true,
-					},
-					"publish_blocked_as_pending": {
-						Type:     schema.TypeBool,
-						Optional: true,
-					},
-					"separate_pull_request_statuses": {
-						Type:     schema.TypeBool,
-						Optional: true,
-					},
-					"filter_enabled": {
-						Type:     schema.TypeBool,
-						Optional: true,
-					},
 				},
 			},
 		},
+		// settings of the repository provider which the settings blocks don't have (yet), booleans, numbers, lists and
+		// objects are given as JSON
+		"provider_settings": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
 	}
 )
 
 func resourcePipeline() *schema.Resource {
 	resource := schema.Resource{
-		Create: CreatePipeline,
-		Read:   ReadPipeline,
-		Update: UpdatePipeline,
-		Delete: DeletePipeline,
+		Create: withContext(schema.TimeoutCreate, CreatePipeline),
+		Read:   withContext(schema.TimeoutRead, ReadPipeline),
+		Update: withContext(schema.TimeoutUpdate, UpdatePipeline),
+		Delete: withContext(schema.TimeoutDelete, DeletePipeline),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: importPipeline,
+		},
+		Timeouts:      resourceTimeouts(),
+		CustomizeDiff: customdiff.All(customizePipelineDiff, customizeProviderSettingsDiff),
+
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Type:    resourcePipelineV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: upgradePipelineStateV0,
+				Version: 0,
+			},
 		},
 
 		Schema: pipelineSchema,
@@ -283,71 +351,182 @@ func resourcePipeline() *schema.Resource {
 	return &resource
 }
 
-func CreatePipeline(d *schema.ResourceData, meta interface{}) error {
+func CreatePipeline(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] CreatePipeline")
 
 	buildkiteClient := meta.(*client.Client)
 
-	pipeline, _ := preparePipelineRequestPayload(d)
+	pipeline, _, err := preparePipelineRequestPayload(d)
+	if err != nil {
+		return err
+	}
 
-	res, err := buildkiteClient.CreatePipeline(pipeline)
+	res, err := buildkiteClient.CreatePipeline(ctx, pipeline)
 	if err != nil {
 		return err
 	}
+	// pipelines created via the REST API give every team the same access level
+	if len(pipeline.Teams) != 0 {
+		if err := buildkiteClient.UpdatePipelineTeams(ctx, res.Slug, pipeline.Teams, nil); err != nil {
+			return err
+		}
+	}
+	if res.PipelineTemplateId != "" {
+		// the steps have just been set from the template
+		res.Configuration = ""
+	}
 
 	return updatePipelineFromAPI(d, res)
 }
 
-func ReadPipeline(d *schema.ResourceData, meta interface{}) error {
+func ReadPipeline(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] ReadPipeline")
 
 	buildkiteClient := meta.(*client.Client)
 	slug := d.Id()
 
-	pipeline, err := buildkiteClient.GetPipeline(slug)
+	pipeline, err := buildkiteClient.GetPipeline(ctx, slug)
 	if err != nil {
-		if _, ok := err.(*client.NotFound); ok {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
 			d.SetId("")
 			return nil
 		}
 		return err
 	}
 
+	if pipeline.PipelineTemplateId != "" {
+		if err := compareWithPipelineTemplate(ctx, buildkiteClient, pipeline); err != nil {
+			return err
+		}
+	}
+
 	return updatePipelineFromAPI(d, pipeline)
 }
 
-func UpdatePipeline(d *schema.ResourceData, meta interface{}) error {
+func UpdatePipeline(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] UpdatePipeline")
 
 	buildkiteClient := meta.(*client.Client)
 
-	pipeline, teamIDsHaveChanged := preparePipelineRequestPayload(d)
-	if teamIDsHaveChanged {
-		return errors.New("unable to update 'team_ids', consider to delete the pipeline and create the new one")
+	pipeline, teamsHaveChanged, err := preparePipelineRequestPayload(d)
+	if err != nil {
+		return err
 	}
 
-	res, err := buildkiteClient.UpdatePipeline(pipeline)
+	res, err := buildkiteClient.UpdatePipeline(ctx, pipeline)
 	if err != nil {
 		return err
 	}
+	// only the teams which changed are updated, teams given access by buildkite_team_pipeline are left alone
+	if teamsHaveChanged {
+		changed, removed := pipelineTeamChanges(d)
+		if err := buildkiteClient.UpdatePipelineTeams(ctx, res.Slug, changed, removed); err != nil {
+			return err
+		}
+	}
+	if res.PipelineTemplateId != "" {
+		// the steps have just been set from the template
+		res.Configuration = ""
+	}
 
 	return updatePipelineFromAPI(d, res)
 }
 
-func DeletePipeline(d *schema.ResourceData, meta interface{}) error {
+// importPipeline reads all teams with access to the imported pipeline into team_ids, later reads only refresh the
+// teams which are already in the state
+func importPipeline(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	buildkiteClient := meta.(*client.Client)
+	ctx, cancel := context.WithTimeout(buildkiteClient.StopContext(), d.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	pipeline, err := buildkiteClient.GetPipeline(ctx, d.Id())
+	if err != nil {
+		return nil, err
+	}
+	d.Set("team_ids", pipeline.TeamIDs)
+	// Read keeps the value in the state, an imported pipeline starts with the default
+	d.Set("migrate_legacy_steps", false)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// pipelineTeamChanges compares the teams in the state with the configured ones and returns the teams which were added
+// or changed their access level and the ids of the removed teams
+func pipelineTeamChanges(d *schema.ResourceData) ([]client.PipelineTeam, []string) {
+	oldTeamIds, newTeamIds := d.GetChange("team_ids")
+	oldTeams, newTeams := d.GetChange("team")
+	previous := pipelineTeamAccess(oldTeamIds.(*schema.Set), oldTeams.(*schema.Set))
+	configured := pipelineTeamAccess(newTeamIds.(*schema.Set), newTeams.(*schema.Set))
+
+	changed := []client.PipelineTeam{}
+	for teamId, accessLevel := range configured {
+		if previous[teamId] != accessLevel {
+			changed = append(changed, client.PipelineTeam{TeamId: teamId, AccessLevel: accessLevel})
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].TeamId < changed[j].TeamId })
+
+	removed := []string{}
+	for teamId := range previous {
+		if _, ok := configured[teamId]; !ok {
+			removed = append(removed, teamId)
+		}
+	}
+	sort.Strings(removed)
+
+	return changed, removed
+}
+
+// pipelineTeamAccess returns the access level of each team given by team_ids or team
+func pipelineTeamAccess(teamIds *schema.Set, teams *schema.Set) map[string]string {
+	access := map[string]string{}
+	for _, teamId := range teamIds.List() {
+		access[teamId.(string)] = client.TeamPipelineAccessManage
+	}
+	for _, teamI := range teams.List() {
+		team := teamI.(map[string]interface{})
+		access[team["team_id"].(string)] = team["access_level"].(string)
+	}
+	return access
+}
+
+func DeletePipeline(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] DeletePipeline")
 
 	buildkiteClient := meta.(*client.Client)
 	slug := d.Id()
 
-	return buildkiteClient.DeletePipeline(slug)
+	return buildkiteClient.DeletePipeline(ctx, slug)
+}
+
+// compareWithPipelineTemplate clears the configuration of a pipeline which uses a template if its steps are the ones
+// of the template, so that configuration only shows a difference once the steps drifted from the template
+func compareWithPipelineTemplate(ctx context.Context, buildkiteClient *client.Client, pipeline *client.Pipeline) error {
+	template, err := buildkiteClient.GetPipelineTemplate(ctx, pipeline.PipelineTemplateId)
+	if err != nil {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		return err
+	}
+
+	if equivalentPipelineConfiguration(template.Configuration, pipeline.Configuration) {
+		pipeline.Configuration = ""
+	}
+	return nil
 }
 
 func updatePipelineFromAPI(d *schema.ResourceData, p *client.Pipeline) error {
 	d.SetId(p.Slug)
 	log.Printf("[INFO] buildkite: Pipeline ID: %s", d.Id())
 
-	d.Set("env", p.Environment)
+	// the step and env attributes of a migrated pipeline stay as they are while its YAML steps are equivalent to them
+	migrated := migratedLegacySteps(d, p)
+	if !migrated {
+		d.Set("env", p.Environment)
+	}
 	d.Set("name", p.Name)
 	d.Set("description", p.Description)
 	d.Set("repository", p.Repository)
@@ -356,9 +535,44 @@ func updatePipelineFromAPI(d *schema.ResourceData, p *client.Pipeline) error {
 	d.Set("builds_url", p.BuildsURL)
 	d.Set("branch_configuration", p.BranchConfiguration)
 	d.Set("default_branch", p.DefaultBranch)
-	d.Set("configuration", p.Configuration)
-	d.Set("team_ids", p.TeamIDs)
-	log.Printf("[TRACE] set pipeline team uuids: %v", p.TeamIDs)
+	d.Set("cluster_id", p.ClusterId)
+	configuration := p.Configuration
+	if configuration == "" && p.PipelineTemplateId == "" && (len(p.Steps) > 0 || len(p.Environment) > 0) {
+		legacy, err := renderLegacyPipelineSteps(p.Steps, p.Environment)
+		if err != nil {
+			log.Printf("[WARN] buildkite: the steps of pipeline %s can't be converted to YAML: %s", p.Slug, err)
+		}
+		configuration = legacy
+	}
+	d.Set("configuration", pipelineConfigurationFromAPI(d, configuration))
+	d.Set("pipeline_template_id", p.PipelineTemplateId)
+	// only the teams this resource manages are read back, not those given access by buildkite_team_pipeline
+	managed := pipelineTeamAccess(d.Get("team_ids").(*schema.Set), d.Get("team").(*schema.Set))
+	if _, ok := d.GetOk("team"); ok {
+		teams := []interface{}{}
+		for _, team := range p.Teams {
+			if _, ok := managed[team.TeamId]; !ok {
+				continue
+			}
+			teams = append(teams, map[string]interface{}{
+				"team_id":      team.TeamId,
+				"access_level": team.AccessLevel,
+			})
+		}
+		if err := d.Set("team", teams); err != nil {
+			return err
+		}
+		log.Printf("[TRACE] set pipeline teams: %v", teams)
+	} else {
+		teamIDs := []string{}
+		for _, teamID := range p.TeamIDs {
+			if _, ok := managed[teamID]; ok {
+				teamIDs = append(teamIDs, teamID)
+			}
+		}
+		d.Set("team_ids", teamIDs)
+		log.Printf("[TRACE] set pipeline team uuids: %v", teamIDs)
+	}
 
 	stepMap := make([]interface{}, len(p.Steps))
 	for i, element := range p.Steps {
@@ -375,13 +589,16 @@ func updatePipelineFromAPI(d *schema.ResourceData, p *client.Pipeline) error {
 			"timeout_in_minutes":   element.TimeoutInMinutes,
 		}
 	}
-	if err := d.Set("step", stepMap); err != nil {
-		return err
+	if !migrated {
+		if err := d.Set("step", stepMap); err != nil {
+			return err
+		}
 	}
 
 	emptySettings := make([]interface{}, 0)
-	d.Set("github_settings", emptySettings)
-	d.Set("bitbucket_settings", emptySettings)
+	for _, name := range providerSettingsAttributes {
+		d.Set(name, emptySettings)
+	}
 
 	log.Printf("[INFO] buildkite: RepositoryProviderId: %s", p.Provider.Id)
 
@@ -394,17 +611,44 @@ func updatePipelineFromAPI(d *schema.ResourceData, p *client.Pipeline) error {
 			return err
 		}
 
+	case "github_enterprise":
+		log.Printf("[DEBUG] buildkite: Provider.Settings in github_enterprise: %+v", p.Provider.Settings)
+		if err := d.Set("github_enterprise_settings", filterProviderSettings("github_enterprise_settings", p.Provider.Settings)); err != nil {
+			return err
+		}
+
 	case "bitbucket":
 		log.Printf("[DEBUG] buildkite: Provider.Settings in bitbucket: %+v", p.Provider.Settings)
 		if err := d.Set("bitbucket_settings", filterProviderSettings("bitbucket_settings", p.Provider.Settings)); err != nil {
 			return err
 		}
 
-	case "gitlab": // noop
+	// gitlab_ee is a self-managed GitLab installation
+	case "gitlab", "gitlab_ee":
+		log.Printf("[DEBUG] buildkite: Provider.Settings in %s: %+v", p.Provider.Id, p.Provider.Settings)
+		if err := d.Set("gitlab_settings", filterProviderSettings("gitlab_settings", p.Provider.Settings)); err != nil {
+			return err
+		}
+
 	case "beanstalk": // noop
 	default: // unknown, noop
 	}
 
+	// only the settings given by provider_settings are read back, the settings blocks show the others
+	providerSettings := map[string]interface{}{}
+	for key, previous := range d.Get("provider_settings").(map[string]interface{}) {
+		if value, ok := p.Provider.Settings[key]; ok && value != nil {
+			setting, err := providerSettingFromAPI(previous.(string), value)
+			if err != nil {
+				return err
+			}
+			providerSettings[key] = setting
+		}
+	}
+	if err := d.Set("provider_settings", providerSettings); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -451,7 +695,73 @@ func contains(strings []string, value string) bool {
 	return false
 }
 
-func preparePipelineRequestPayload(d *schema.ResourceData) (*client.Pipeline, bool) {
+// githubSettingsResource describes the settings of GitHub and GitHub Enterprise repositories, which Buildkite treats
+// the same way
+func githubSettingsResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"trigger_mode": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "code",
+			},
+			"build_pull_requests": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"pull_request_branch_filter_enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"pull_request_branch_filter_configuration": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"skip_pull_request_builds_for_existing_commits": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"build_pull_request_forks": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"prefix_pull_request_fork_branch_names": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"build_tags": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"publish_commit_status": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"publish_commit_status_per_step": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"publish_blocked_as_pending": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"separate_pull_request_statuses": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"filter_enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+		},
+	}
+}
+
+func preparePipelineRequestPayload(d *schema.ResourceData) (*client.Pipeline, bool, error) {
 	req := &client.Pipeline{}
 
 	req.Name = d.Get("name").(string)
@@ -460,63 +770,74 @@ func preparePipelineRequestPayload(d *schema.ResourceData) (*client.Pipeline, bo
 	req.Slug = d.Get("slug").(string)
 	req.Repository = d.Get("repository").(string)
 	req.BranchConfiguration = d.Get("branch_configuration").(string)
+	req.ClusterId = d.Get("cluster_id").(string)
 	req.Environment = map[string]string{}
 	for k, vI := range d.Get("env").(map[string]interface{}) {
 		req.Environment[k] = vI.(string)
 	}
-	teamIDs := d.Get("team_ids").(*schema.Set).List()
-	req.TeamIDs = make([]string, len(teamIDs))
-	for i, t := range teamIDs {
-		req.TeamIDs[i] = t.(string)
+	for _, t := range d.Get("team_ids").(*schema.Set).List() {
+		req.Teams = append(req.Teams, client.PipelineTeam{TeamId: t.(string), AccessLevel: client.TeamPipelineAccessManage})
+	}
+	for _, teamI := range d.Get("team").(*schema.Set).List() {
+		team := teamI.(map[string]interface{})
+		req.Teams = append(req.Teams, client.PipelineTeam{
+			TeamId:      team["team_id"].(string),
+			AccessLevel: team["access_level"].(string),
+		})
+	}
+	req.TeamIDs = make([]string, len(req.Teams))
+	for i, team := range req.Teams {
+		req.TeamIDs[i] = team.TeamId
 	}
 	log.Printf("[TRACE] pull team ids from schema: %v", req.TeamIDs)
 
-	if val, ok := d.GetOk("configuration"); ok {
+	legacySteps, _ := d.Get("step").([]interface{})
+	if val, ok := d.GetOk("pipeline_template_id"); ok {
+		req.PipelineTemplateId = val.(string)
+	} else if val, ok := d.GetOk("steps"); ok {
+		configuration, err := renderPipelineSteps(val.([]interface{}))
+		if err != nil {
+			return nil, false, err
+		}
+		req.Configuration = configuration
+	} else if val, ok := d.GetOk("configuration"); ok && len(legacySteps) == 0 && len(req.Environment) == 0 {
 		req.Configuration = val.(string)
-	} else {
-		stepsI := d.Get("step").([]interface{})
-		req.Steps = make([]client.Step, len(stepsI))
-
-		for i, stepI := range stepsI {
-			stepM := stepI.(map[string]interface{})
-			req.Steps[i] = client.Step{
-				Type:                stepM["type"].(string),
-				Name:                stepM["name"].(string),
-				Command:             stepM["command"].(string),
-				Environment:         map[string]string{},
-				AgentQueryRules:     make([]string, len(stepM["agent_query_rules"].([]interface{}))),
-				BranchConfiguration: stepM["branch_configuration"].(string),
-				ArtifactPaths:       stepM["artifact_paths"].(string),
-				Concurrency:         stepM["concurrency"].(int),
-				Parallelism:         stepM["parallelism"].(int),
-				TimeoutInMinutes:    stepM["timeout_in_minutes"].(int),
-			}
-
-			for k, vI := range stepM["env"].(map[string]interface{}) {
-				req.Steps[i].Environment[k] = vI.(string)
-			}
-
-			for j, vI := range stepM["agent_query_rules"].([]interface{}) {
-				req.Steps[i].AgentQueryRules[j] = vI.(string)
-			}
+	} else if d.Get("migrate_legacy_steps").(bool) && (len(legacySteps) > 0 || len(req.Environment) > 0) {
+		// the YAML steps are saved via the GraphQL API, which switches the pipeline over from its legacy steps
+		configuration, err := renderLegacyPipelineSteps(legacyPipelineSteps(legacySteps), req.Environment)
+		if err != nil {
+			return nil, false, err
 		}
+		req.Configuration = configuration
+		req.Environment = map[string]string{}
+	} else {
+		req.Steps = legacyPipelineSteps(legacySteps)
 	}
 
-	if d.HasChange("github_settings") || d.HasChange("bitbucket_settings") {
+	// the pipeline keeps its template until it is removed explicitly or replaced by YAML steps
+	req.DetachPipelineTemplate = d.HasChange("pipeline_template_id") && req.PipelineTemplateId == ""
+	// and its YAML steps until they are removed or replaced by legacy steps
+	req.ClearConfiguration = d.HasChange("configuration") && req.Configuration == "" && req.PipelineTemplateId == ""
+
+	// Buildkite resets the settings when the repository moves to another provider
+	if d.HasChanges(providerSettingsAttributes...) || d.HasChange("provider_settings") || d.HasChange("repository") {
 		log.Printf("[INFO] buildkite: RepositoryProviderSettings have changed")
 
-		githubSettings := d.Get("github_settings").([]interface{})
-		bitbucketSettings := d.Get("bitbucket_settings").([]interface{})
 		settings := map[string]interface{}{}
-
-		if len(githubSettings) > 0 {
-			settings = githubSettings[0].(map[string]interface{})
-		} else if len(bitbucketSettings) > 0 {
-			settings = bitbucketSettings[0].(map[string]interface{})
+		for _, name := range providerSettingsAttributes {
+			if blocks := d.Get(name).([]interface{}); len(blocks) > 0 && blocks[0] != nil {
+				for key, value := range blocks[0].(map[string]interface{}) {
+					settings[key] = value
+				}
+				break
+			}
+		}
+		for key, value := range d.Get("provider_settings").(map[string]interface{}) {
+			settings[key] = providerSettingValue(value.(string))
 		}
 
 		req.ProviderSettings = settings
 	}
 
-	return req, d.HasChange("team_ids")
+	return req, d.HasChange("team_ids") || d.HasChange("team"), nil
 }
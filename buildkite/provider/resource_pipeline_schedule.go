@@ -1,6 +1,8 @@
 package provider
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -12,13 +14,14 @@ import (
 
 func resourcePipelineSchedule() *schema.Resource {
 	return &schema.Resource{
-		Create: CreatePipelineSchedule,
-		Read:   ReadPipelineSchedule,
-		Update: UpdatePipelineSchedule,
-		Delete: DeletePipelineSchedule,
+		Create: withContext(schema.TimeoutCreate, CreatePipelineSchedule),
+		Read:   withContext(schema.TimeoutRead, ReadPipelineSchedule),
+		Update: withContext(schema.TimeoutUpdate, UpdatePipelineSchedule),
+		Delete: withContext(schema.TimeoutDelete, DeletePipelineSchedule),
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"pipeline_slug": {
@@ -77,14 +80,14 @@ func resourcePipelineSchedule() *schema.Resource {
 	}
 }
 
-func CreatePipelineSchedule(d *schema.ResourceData, meta interface{}) error {
+func CreatePipelineSchedule(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] CreatePipelineSchedule")
 
 	buildkiteClient := meta.(*client.Client)
 
 	pipelineSchedule := preparePipelineScheduleRequestPayload(d)
 
-	res, err := buildkiteClient.CreatePipelineSchedule(pipelineSchedule)
+	res, err := buildkiteClient.CreatePipelineSchedule(ctx, pipelineSchedule)
 	if err != nil {
 		return err
 	}
@@ -92,15 +95,16 @@ func CreatePipelineSchedule(d *schema.ResourceData, meta interface{}) error {
 	return updatePipelineScheduleFromAPI(d, res)
 }
 
-func ReadPipelineSchedule(d *schema.ResourceData, meta interface{}) error {
+func ReadPipelineSchedule(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] ReadPipelineSchedule")
 
 	buildkiteClient := meta.(*client.Client)
 	memberId := d.Id()
 
-	pipelineSchedule, err := buildkiteClient.GetPipelineSchedule(memberId)
+	pipelineSchedule, err := buildkiteClient.GetPipelineSchedule(ctx, memberId)
 	if err != nil {
-		if _, ok := err.(*client.NotFound); ok {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
 			d.SetId("")
 			return nil
 		}
@@ -110,14 +114,14 @@ func ReadPipelineSchedule(d *schema.ResourceData, meta interface{}) error {
 	return updatePipelineScheduleFromAPI(d, pipelineSchedule)
 }
 
-func UpdatePipelineSchedule(d *schema.ResourceData, meta interface{}) error {
+func UpdatePipelineSchedule(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] UpdatePipelineSchedule")
 
 	buildkiteClient := meta.(*client.Client)
 
 	pipelineSchedule := preparePipelineScheduleRequestPayload(d)
 
-	res, err := buildkiteClient.UpdatePipelineSchedule(pipelineSchedule)
+	res, err := buildkiteClient.UpdatePipelineSchedule(ctx, pipelineSchedule)
 	if err != nil {
 		return err
 	}
@@ -125,13 +129,13 @@ func UpdatePipelineSchedule(d *schema.ResourceData, meta interface{}) error {
 	return updatePipelineScheduleFromAPI(d, res)
 }
 
-func DeletePipelineSchedule(d *schema.ResourceData, meta interface{}) error {
+func DeletePipelineSchedule(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] DeletePipelineSchedule")
 
 	buildkiteClient := meta.(*client.Client)
 	id := d.Get("schedule_id").(string)
 
-	return buildkiteClient.DeletePipelineSchedule(id)
+	return buildkiteClient.DeletePipelineSchedule(ctx, id)
 }
 
 func updatePipelineScheduleFromAPI(d *schema.ResourceData, t *client.PipelineSchedule) error {
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	"github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+func resourcePipelineTemplate() *schema.Resource {
+	return &schema.Resource{
+		Create: withContext(schema.TimeoutCreate, CreatePipelineTemplate),
+		Read:   withContext(schema.TimeoutRead, ReadPipelineTemplate),
+		Update: withContext(schema.TimeoutUpdate, UpdatePipelineTemplate),
+		Delete: withContext(schema.TimeoutDelete, DeletePipelineTemplate),
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Timeouts: resourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"configuration": {
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressEquivalentPipelineConfiguration,
+				ValidateFunc:     validatePipelineConfiguration,
+			},
+			"available": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreatePipelineTemplate(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] CreatePipelineTemplate")
+
+	buildkiteClient := meta.(*client.Client)
+
+	res, err := buildkiteClient.CreatePipelineTemplate(ctx, preparePipelineTemplateRequestPayload(d))
+	if err != nil {
+		return err
+	}
+
+	return updatePipelineTemplateFromAPI(d, res)
+}
+
+func ReadPipelineTemplate(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] ReadPipelineTemplate")
+
+	buildkiteClient := meta.(*client.Client)
+
+	template, err := buildkiteClient.GetPipelineTemplate(ctx, d.Id())
+	if err != nil {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	return updatePipelineTemplateFromAPI(d, template)
+}
+
+func UpdatePipelineTemplate(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] UpdatePipelineTemplate")
+
+	buildkiteClient := meta.(*client.Client)
+
+	template := preparePipelineTemplateRequestPayload(d)
+	template.Id = d.Id()
+
+	res, err := buildkiteClient.UpdatePipelineTemplate(ctx, template)
+	if err != nil {
+		return err
+	}
+
+	return updatePipelineTemplateFromAPI(d, res)
+}
+
+func DeletePipelineTemplate(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] DeletePipelineTemplate")
+
+	buildkiteClient := meta.(*client.Client)
+
+	return buildkiteClient.DeletePipelineTemplate(ctx, d.Id())
+}
+
+func updatePipelineTemplateFromAPI(d *schema.ResourceData, t *client.PipelineTemplate) error {
+	d.SetId(t.Id)
+	log.Printf("[INFO] buildkite: pipeline template ID: %s", d.Id())
+
+	d.Set("uuid", t.UUID)
+	d.Set("name", t.Name)
+	d.Set("description", t.Description)
+	d.Set("configuration", pipelineConfigurationFromAPI(d, t.Configuration))
+	d.Set("available", t.Available)
+
+	return nil
+}
+
+func preparePipelineTemplateRequestPayload(d *schema.ResourceData) *client.PipelineTemplate {
+	return &client.PipelineTemplate{
+		Name:          d.Get("name").(string),
+		Description:   d.Get("description").(string),
+		Configuration: d.Get("configuration").(string),
+		Available:     d.Get("available").(bool),
+	}
+}
@@ -0,0 +1,184 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+
+	buildkiteClient "github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+func TestAccPipelineTemplate_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineTemplateDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPipelineTemplate_basic("echo 'Hello World'", false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkitePipelineTemplateExists("buildkite_pipeline_template.test"),
+					resource.TestCheckResourceAttr("buildkite_pipeline_template.test", "name", "tf-acc-template"),
+					resource.TestCheckResourceAttr("buildkite_pipeline_template.test", "description", "Standard steps"),
+					resource.TestCheckResourceAttr("buildkite_pipeline_template.test", "available", "false"),
+					resource.TestCheckResourceAttrSet("buildkite_pipeline_template.test", "uuid"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccPipelineTemplate_basic("make test", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline_template.test", "configuration", "steps:\n  - command: make test\n"),
+					resource.TestCheckResourceAttr("buildkite_pipeline_template.test", "available", "true"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      "buildkite_pipeline_template.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccPipelineTemplate_pipeline(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPipelineTemplate_pipeline("echo 'Hello World'"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkitePipelineExists("buildkite_pipeline.test"),
+					resource.TestCheckResourceAttrPair("buildkite_pipeline.test", "pipeline_template_id", "buildkite_pipeline_template.test", "id"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "configuration", ""),
+				),
+			},
+			resource.TestStep{
+				// changes of the template reach the pipeline without a difference on the pipeline
+				Config: testAccPipelineTemplate_pipeline("make test"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "configuration", ""),
+				),
+			},
+			resource.TestStep{
+				// someone replaced the steps of the pipeline by hand
+				PreConfig: func() {
+					client := testAccProvider.Meta().(*buildkiteClient.Client)
+					_, err := client.UpdatePipeline(context.Background(), &buildkiteClient.Pipeline{
+						Slug:          "tf-acc-template-pipeline",
+						Configuration: "steps:\n  - command: echo 'Skip the tests'\n",
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             testAccPipelineTemplate_pipeline("make test"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			resource.TestStep{
+				Config: testAccPipelineTemplate_pipeline("make test"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("buildkite_pipeline.test", "pipeline_template_id", "buildkite_pipeline_template.test", "id"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "configuration", ""),
+				),
+			},
+			resource.TestStep{
+				// removing the template detaches the pipeline, even without YAML steps replacing it
+				Config: testAccPipelineTemplate_detachedPipeline(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "pipeline_template_id", ""),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "step.#", "1"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "step.0.command", "make lint"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBuildkitePipelineTemplateExists(id string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found: %s", id)
+		}
+
+		_, err := client.GetPipelineTemplate(context.Background(), rs.Primary.ID)
+		return err
+	}
+}
+
+func testAccCheckBuildkitePipelineTemplateDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "buildkite_pipeline_template" {
+			continue
+		}
+
+		_, err := client.GetPipelineTemplate(context.Background(), rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Pipeline template still exists")
+		}
+
+		var notFound *buildkiteClient.NotFound
+		if !errors.As(err, &notFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testAccPipelineTemplate_basic(command string, available bool) string {
+	return fmt.Sprintf(`
+resource "buildkite_pipeline_template" "test" {
+  name          = "tf-acc-template"
+  description   = "Standard steps"
+  configuration = "steps:\n  - command: %s\n"
+  available     = %t
+}
+`, command, available)
+}
+
+func testAccPipelineTemplate_pipeline(command string) string {
+	return fmt.Sprintf(`
+resource "buildkite_pipeline_template" "test" {
+  name          = "tf-acc-template-for-pipeline"
+  configuration = "steps:\n  - command: %s\n"
+}
+
+resource "buildkite_pipeline" "test" {
+  name                 = "tf-acc-template-pipeline"
+  repository           = "git@github.com:saymedia/terraform-provider-buildkite.git"
+  pipeline_template_id = buildkite_pipeline_template.test.id
+}
+`, command)
+}
+
+func testAccPipelineTemplate_detachedPipeline() string {
+	return `
+resource "buildkite_pipeline_template" "test" {
+  name          = "tf-acc-template-for-pipeline"
+  configuration = "steps:\n  - command: make test\n"
+}
+
+resource "buildkite_pipeline" "test" {
+  name       = "tf-acc-template-pipeline"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+
+  step {
+    type    = "script"
+    name    = "Lint"
+    command = "make lint"
+  }
+}
+`
+}
@@ -1,7 +1,11 @@
 package provider
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -50,6 +54,7 @@ func TestAccPipeline_basic_beanstalk(t *testing.T) {
 					resource.TestCheckResourceAttrSet("buildkite_pipeline.test_gitlab", "webhook_url"),
 					resource.TestCheckResourceAttr("buildkite_pipeline.test_gitlab", "github_settings.#", "0"),
 					resource.TestCheckResourceAttr("buildkite_pipeline.test_gitlab", "bitbucket_settings.#", "0"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_gitlab", "gitlab_settings.#", "1"),
 				),
 			},
 		},
@@ -121,6 +126,573 @@ func TestAccPipeline_basic_gitlab(t *testing.T) {
 	})
 }
 
+func TestAccPipeline_gitlabSettings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPipeline_gitlabSettings("gitlab.com"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "gitlab_settings.#", "1"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "gitlab_settings.0.build_tags", "true"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "gitlab_settings.0.build_pull_requests", "false"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "gitlab_settings.0.publish_commit_status", "true"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "github_settings.#", "0"),
+				),
+			},
+			resource.TestStep{
+				// a self-managed GitLab installation
+				Config: testAccPipeline_gitlabSettings("gitlab.example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "gitlab_settings.#", "1"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "gitlab_settings.0.build_tags", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPipeline_githubEnterpriseSettings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPipeline_githubEnterpriseSettings,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "github_enterprise_settings.#", "1"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "github_enterprise_settings.0.trigger_mode", "deployment"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "github_enterprise_settings.0.build_pull_requests", "true"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "github_settings.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPipeline_providerSettings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPipeline_providerSettings("build.branch == 'main'"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "provider_settings.%", "4"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "provider_settings.filter_condition", "build.branch == 'main'"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "provider_settings.build_branches", "false"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "provider_settings.cancel_after_minutes", "10"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "provider_settings.ignored_branches", `["gh-pages","wip/*"]`),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "github_settings.0.build_tags", "true"),
+					func(s *terraform.State) error {
+						client := testAccProvider.Meta().(*buildkiteClient.Client)
+						pipeline, err := client.GetPipeline(context.Background(), "tf-acc-foo")
+						if err != nil {
+							return err
+						}
+						if pipeline.Provider.Settings["build_branches"] != false {
+							return fmt.Errorf("expected build_branches to be sent as a boolean, got %#v", pipeline.Provider.Settings["build_branches"])
+						}
+						if pipeline.Provider.Settings["cancel_after_minutes"] != float64(10) {
+							return fmt.Errorf("expected cancel_after_minutes to be sent as a number, got %#v", pipeline.Provider.Settings["cancel_after_minutes"])
+						}
+						ignored := []interface{}{"gh-pages", "wip/*"}
+						if !reflect.DeepEqual(pipeline.Provider.Settings["ignored_branches"], ignored) {
+							return fmt.Errorf("expected ignored_branches to be sent as a list, got %#v", pipeline.Provider.Settings["ignored_branches"])
+						}
+						return nil
+					},
+				),
+			},
+			resource.TestStep{
+				Config: testAccPipeline_providerSettings("build.tag != null"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_foo", "provider_settings.filter_condition", "build.tag != null"),
+				),
+			},
+			resource.TestStep{
+				Config:   testAccPipeline_providerSettings("build.tag != null"),
+				PlanOnly: true,
+			},
+			resource.TestStep{
+				Config:      testAccPipeline_providerSettingsDuplicate,
+				ExpectError: regexp.MustCompile("provider_settings: build_tags can't be given, use github_settings instead"),
+			},
+		},
+	})
+}
+
+func TestAccPipeline_configurationFormatting(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				// Buildkite sorts the keys and drops the quotes and the comment, the configuration keeps its formatting
+				Config: testAccPipeline_configurationFormatting(`
+steps:
+    - label: "Tests"
+      command: "make test" # all of them
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "configuration",
+						"steps:\n    - label: \"Tests\"\n      command: \"make test\" # all of them\n"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccPipeline_configurationFormatting(`
+steps:
+  - command: make test
+    label: Tests
+`),
+				PlanOnly: true,
+			},
+			resource.TestStep{
+				Config: testAccPipeline_configurationFormatting(`
+steps:
+    - label: "Tests"
+      command: "make lint test"
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "configuration",
+						"steps:\n    - label: \"Tests\"\n      command: \"make lint test\"\n"),
+					func(s *terraform.State) error {
+						client := testAccProvider.Meta().(*buildkiteClient.Client)
+						pipeline, err := client.GetPipeline(context.Background(), "tf-acc-configuration-formatting")
+						if err != nil {
+							return err
+						}
+						if !strings.Contains(pipeline.Configuration, "make lint test") {
+							return fmt.Errorf("expected the steps to be updated, got %s", pipeline.Configuration)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccPipeline_configurationRemoved(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPipeline_configurationFormatting(`
+steps:
+  - command: make test
+`),
+			},
+			resource.TestStep{
+				// removing the configuration removes the steps of the pipeline instead of keeping them
+				Config: testAccPipeline_configurationRemoved,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "configuration", ""),
+					func(s *terraform.State) error {
+						client := testAccProvider.Meta().(*buildkiteClient.Client)
+						pipeline, err := client.GetPipeline(context.Background(), "tf-acc-configuration-formatting")
+						if err != nil {
+							return err
+						}
+						if pipeline.Configuration != "" {
+							return fmt.Errorf("expected the steps to be removed, got %s", pipeline.Configuration)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccPipeline_configurationValidation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPipeline_configurationFormatting(`
+steps:
+  - label: Tests
+    comand: make test
+`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("line 3, column 5: additionalProperties 'comand' not allowed"),
+			},
+			resource.TestStep{
+				// the steps are only known once the team exists, they are sent to Buildkite without validation
+				Config: testAccPipeline_configurationUnknown,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkitePipelineExists("buildkite_pipeline.test"),
+					resource.TestMatchResourceAttr("buildkite_pipeline.test", "configuration", regexp.MustCompile(`echo [0-9a-f-]{36}`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPipeline_steps(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPipeline_steps(`"make test"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkitePipelineExists("buildkite_pipeline.test"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "configuration", `steps:
+  - label: Tests
+    command: make test
+    key: tests
+    plugins:
+      - docker#v5.9.0:
+          image: golang
+    agents:
+      queue: default
+    retry:
+      automatic:
+        - exit_status: -1
+          limit: 2
+  - wait
+  - group: Deploy
+    steps:
+      - block: Deploy?
+        fields:
+          - select: Region
+            key: region
+            options:
+              - label: Europe
+                value: eu
+      - trigger: deploy
+        depends_on:
+          - tests
+        build:
+          branch: main
+`),
+				),
+			},
+			resource.TestStep{
+				// Buildkite reformats the steps it was sent
+				Config:   testAccPipeline_steps(`"make test"`),
+				PlanOnly: true,
+			},
+			resource.TestStep{
+				Config: testAccPipeline_steps(`"make lint test"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("buildkite_pipeline.test", "configuration", regexp.MustCompile(`command: make lint test\n`)),
+					func(s *terraform.State) error {
+						client := testAccProvider.Meta().(*buildkiteClient.Client)
+						pipeline, err := client.GetPipeline(context.Background(), "tf-acc-steps")
+						if err != nil {
+							return err
+						}
+						if !strings.Contains(pipeline.Configuration, "make lint test") {
+							return fmt.Errorf("expected the steps to be updated, got %s", pipeline.Configuration)
+						}
+						return nil
+					},
+				),
+			},
+			resource.TestStep{
+				// the configuration is only known once the team exists
+				Config: testAccPipeline_steps(`"echo ${buildkite_team.test.uuid}"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("buildkite_pipeline.test", "configuration", regexp.MustCompile(`command: echo [0-9a-f-]{36}\n`)),
+				),
+			},
+			resource.TestStep{
+				Config:      testAccPipeline_stepsWithoutKind,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("steps.0: a step needs exactly one of command, wait, block, input, trigger, group, got 2"),
+			},
+		},
+	})
+}
+
+func TestAccPipeline_migrateLegacySteps(t *testing.T) {
+	legacyConfiguration := `env:
+  DEBUG: "true"
+steps:
+  - label: Tests
+    command: make test
+    agents:
+      queue: default
+  - wait
+  - block: Deploy?
+    branches: main
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPipeline_legacySteps(false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "step.#", "3"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "configuration", legacyConfiguration),
+					testAccCheckBuildkitePipelineLegacySteps("tf-acc-legacy-steps", 3),
+				),
+			},
+			resource.TestStep{
+				Config: testAccPipeline_legacySteps(true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "step.#", "3"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "env.DEBUG", "true"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "configuration", legacyConfiguration),
+					testAccCheckBuildkitePipelineLegacySteps("tf-acc-legacy-steps", 0),
+				),
+			},
+			resource.TestStep{
+				Config:   testAccPipeline_legacySteps(true),
+				PlanOnly: true,
+			},
+			resource.TestStep{
+				// the YAML steps replace the legacy steps without changing the pipeline
+				Config: testAccPipeline_migratedLegacySteps(legacyConfiguration),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "step.#", "0"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test", "configuration", legacyConfiguration),
+					testAccCheckBuildkitePipelineLegacySteps("tf-acc-legacy-steps", 0),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPipeline_teams(t *testing.T) {
+	var webhookURL string
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPipeline_teams("buildkite_team.test[*].team_id"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkitePipelineExists("buildkite_pipeline.test_teams"),
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_teams", "team_ids.#", "3"),
+					testAccCheckBuildkitePipelineAttr("buildkite_pipeline.test_teams", "webhook_url", &webhookURL),
+				),
+			},
+			resource.TestStep{
+				// teams are added and removed without recreating the pipeline
+				Config: testAccPipeline_teams("[buildkite_team.test[1].team_id, buildkite_team.test[2].team_id]"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_teams", "team_ids.#", "2"),
+					testAccCheckBuildkitePipelineTeams("buildkite_pipeline.test_teams", map[int]string{
+						1: "MANAGE_BUILD_AND_READ",
+						2: "MANAGE_BUILD_AND_READ",
+					}),
+					resource.TestCheckResourceAttrPtr("buildkite_pipeline.test_teams", "webhook_url", &webhookURL),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      "buildkite_pipeline.test_teams",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccPipeline_teamsWithTeamPipeline(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPipeline_teamsWithTeamPipeline("team_ids = [buildkite_team.test[0].team_id]"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_teams", "team_ids.#", "1"),
+					testAccCheckBuildkitePipelineTeams("buildkite_pipeline.test_teams", map[int]string{
+						0: "MANAGE_BUILD_AND_READ",
+						1: "READ_ONLY",
+					}),
+				),
+			},
+			resource.TestStep{
+				// the team given access by buildkite_team_pipeline is kept when the teams of the pipeline change
+				Config: testAccPipeline_teamsWithTeamPipeline("team_ids = [buildkite_team.test[2].team_id]"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_teams", "team_ids.#", "1"),
+					testAccCheckBuildkitePipelineTeams("buildkite_pipeline.test_teams", map[int]string{
+						1: "READ_ONLY",
+						2: "MANAGE_BUILD_AND_READ",
+					}),
+				),
+			},
+			resource.TestStep{
+				// and when they are removed altogether
+				Config: testAccPipeline_teamsWithTeamPipeline(""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_teams", "team_ids.#", "0"),
+					testAccCheckBuildkitePipelineTeams("buildkite_pipeline.test_teams", map[int]string{
+						1: "READ_ONLY",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPipeline_teamAccessLevels(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPipeline_teamAccessLevels("READ_ONLY", "BUILD_AND_READ"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_pipeline.test_teams", "team.#", "2"),
+					testAccCheckBuildkitePipelineTeams("buildkite_pipeline.test_teams", map[int]string{
+						0: "READ_ONLY",
+						1: "BUILD_AND_READ",
+					}),
+				),
+			},
+			resource.TestStep{
+				Config: testAccPipeline_teamAccessLevels("MANAGE_BUILD_AND_READ", "BUILD_AND_READ"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkitePipelineTeams("buildkite_pipeline.test_teams", map[int]string{
+						0: "MANAGE_BUILD_AND_READ",
+						1: "BUILD_AND_READ",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPipeline_duplicateName(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccPipeline_duplicateName,
+				ExpectError: regexp.MustCompile(`status 422.*\n.*name: has already been taken`),
+			},
+		},
+	})
+}
+
+func TestAccPipeline_cluster(t *testing.T) {
+	var webhookURL string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkitePipelineDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccPipeline_cluster("first"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkitePipelineExists("buildkite_pipeline.test_cluster"),
+					resource.TestCheckResourceAttrPair("buildkite_pipeline.test_cluster", "cluster_id", "buildkite_cluster.first", "id"),
+					testAccCheckBuildkitePipelineAttr("buildkite_pipeline.test_cluster", "webhook_url", &webhookURL),
+				),
+			},
+			resource.TestStep{
+				Config: testAccPipeline_cluster("second"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("buildkite_pipeline.test_cluster", "cluster_id", "buildkite_cluster.second", "id"),
+					// every pipeline has its own webhook URL, so it only stays the same if the pipeline was moved
+					// rather than recreated
+					resource.TestCheckResourceAttrPtr("buildkite_pipeline.test_cluster", "webhook_url", &webhookURL),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBuildkitePipelineAttr(id string, key string, value *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found: %s", id)
+		}
+
+		*value = rs.Primary.Attributes[key]
+		return nil
+	}
+}
+
+// testAccCheckBuildkitePipelineTeams checks the access levels of the teams of the pipeline, teams are given by their
+// index in buildkite_team.test
+func testAccCheckBuildkitePipelineTeams(id string, accessLevels map[int]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found: %s", id)
+		}
+
+		res, err := client.GetPipeline(context.Background(), rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if len(res.Teams) != len(accessLevels) {
+			return fmt.Errorf("expected %d teams, got %d", len(accessLevels), len(res.Teams))
+		}
+
+		for index, accessLevel := range accessLevels {
+			team, ok := s.RootModule().Resources[fmt.Sprintf("buildkite_team.test.%d", index)]
+			if !ok {
+				return fmt.Errorf("Not found: buildkite_team.test.%d", index)
+			}
+
+			found := false
+			for _, pipelineTeam := range res.Teams {
+				if pipelineTeam.TeamId != team.Primary.Attributes["team_id"] {
+					continue
+				}
+				found = true
+				if pipelineTeam.AccessLevel != accessLevel {
+					return fmt.Errorf("expected %s access for team %d, got %s", accessLevel, index, pipelineTeam.AccessLevel)
+				}
+			}
+			if !found {
+				return fmt.Errorf("team %d has no access to the pipeline", index)
+			}
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckBuildkitePipelineLegacySteps checks the number of legacy steps of a pipeline, pipelines using YAML
+// steps have none
+func testAccCheckBuildkitePipelineLegacySteps(slug string, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*buildkiteClient.Client)
+		pipeline, err := client.GetPipeline(context.Background(), slug)
+		if err != nil {
+			return err
+		}
+		if len(pipeline.Steps) != count {
+			return fmt.Errorf("expected %d legacy steps, got %d", count, len(pipeline.Steps))
+		}
+		if count == 0 && pipeline.Configuration == "" {
+			return fmt.Errorf("expected the pipeline to use YAML steps")
+		}
+		return nil
+	}
+}
+
 func testAccCheckBuildkitePipelineExists(id string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		client := testAccProvider.Meta().(*buildkiteClient.Client)
@@ -134,7 +706,7 @@ func testAccCheckBuildkitePipelineExists(id string) resource.TestCheckFunc {
 			return fmt.Errorf("No Pipeline ID is set")
 		}
 
-		res, err := client.GetPipeline(rs.Primary.ID)
+		res, err := client.GetPipeline(context.Background(), rs.Primary.ID)
 
 		if err != nil {
 			return err
@@ -159,7 +731,7 @@ func testAccCheckBuildkitePipelineDestroy(s *terraform.State) error {
 			continue
 		}
 
-		res, err := client.GetPipeline(rs.Primary.ID)
+		res, err := client.GetPipeline(context.Background(), rs.Primary.ID)
 		if err == nil {
 			if res.Slug == rs.Primary.ID {
 				return fmt.Errorf("Pipeline still exists")
@@ -167,7 +739,8 @@ func testAccCheckBuildkitePipelineDestroy(s *terraform.State) error {
 		}
 
 		// Verify the error
-		if _, ok := err.(*buildkiteClient.NotFound); !ok {
+		var notFound *buildkiteClient.NotFound
+		if !errors.As(err, &notFound) {
 			return err
 		}
 	}
@@ -319,3 +892,346 @@ resource "buildkite_pipeline" "test_foo" {
   }
 }
 `
+
+func testAccPipeline_gitlabSettings(host string) string {
+	return fmt.Sprintf(`
+resource "buildkite_pipeline" "test_foo" {
+  name       = "tf-acc-foo"
+  repository = "git@%s:terraform-provider-buildkite/terraform-buildkite.git"
+
+  configuration = "steps:\n  - command: echo 'Hello World'\n"
+
+  gitlab_settings {
+    build_tags          = true
+    build_pull_requests = false
+  }
+}
+`, host)
+}
+
+const testAccPipeline_githubEnterpriseSettings = `
+resource "buildkite_pipeline" "test_foo" {
+  name       = "tf-acc-foo"
+  repository = "git@github.example.com:saymedia/terraform-provider-buildkite.git"
+
+  configuration = "steps:\n  - command: echo 'Hello World'\n"
+
+  github_enterprise_settings {
+    trigger_mode = "deployment"
+  }
+}
+`
+
+func testAccPipeline_providerSettings(filterCondition string) string {
+	return fmt.Sprintf(`
+resource "buildkite_pipeline" "test_foo" {
+  name       = "tf-acc-foo"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+
+  configuration = "steps:\n  - command: echo 'Hello World'\n"
+
+  github_settings {
+    build_tags = true
+  }
+
+  provider_settings = {
+    build_branches       = "false"
+    filter_condition     = %q
+    cancel_after_minutes = 10
+    ignored_branches     = jsonencode(["gh-pages", "wip/*"])
+  }
+}
+`, filterCondition)
+}
+
+const testAccPipeline_providerSettingsDuplicate = `
+resource "buildkite_pipeline" "test_foo" {
+  name       = "tf-acc-foo"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+
+  configuration = "steps:\n  - command: echo 'Hello World'\n"
+
+  provider_settings = {
+    build_tags = "true"
+  }
+}
+`
+
+func testAccPipeline_configurationFormatting(configuration string) string {
+	return fmt.Sprintf(`
+resource "buildkite_pipeline" "test" {
+  name       = "tf-acc-configuration-formatting"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+
+  configuration = <<EOT
+%sEOT
+}
+`, strings.TrimPrefix(configuration, "\n"))
+}
+
+const testAccPipeline_configurationRemoved = `
+resource "buildkite_pipeline" "test" {
+  name       = "tf-acc-configuration-formatting"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+}
+`
+
+const testAccPipeline_configurationUnknown = `
+resource "buildkite_team" "test" {
+  name = "tf-acc-configuration-unknown"
+}
+
+resource "buildkite_pipeline" "test" {
+  name       = "tf-acc-configuration-unknown"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+
+  configuration = yamlencode({
+    steps = [{
+      command = "echo ${buildkite_team.test.uuid}"
+      agents  = { queue = buildkite_team.test.slug }
+    }]
+  })
+}
+`
+
+func testAccPipeline_steps(command string) string {
+	return fmt.Sprintf(`
+resource "buildkite_team" "test" {
+  name = "tf-acc-steps"
+}
+
+resource "buildkite_pipeline" "test" {
+  name       = "tf-acc-steps"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+
+  steps {
+    command {
+      label    = "Tests"
+      key      = "tests"
+      commands = [%s]
+      agents   = { queue = "default" }
+
+      plugin {
+        name          = "docker#v5.9.0"
+        configuration = jsonencode({ image = "golang" })
+      }
+
+      retry {
+        automatic {
+          exit_status = "-1"
+          limit       = 2
+        }
+      }
+    }
+  }
+
+  steps {
+    wait {}
+  }
+
+  steps {
+    group {
+      label = "Deploy"
+
+      steps {
+        block {
+          label = "Deploy?"
+
+          field {
+            key    = "region"
+            select = "Region"
+
+            option {
+              label = "Europe"
+              value = "eu"
+            }
+          }
+        }
+      }
+
+      steps {
+        trigger {
+          pipeline   = "deploy"
+          depends_on = ["tests"]
+
+          build {
+            branch = "main"
+          }
+        }
+      }
+    }
+  }
+}
+`, command)
+}
+
+const testAccPipeline_stepsWithoutKind = `
+resource "buildkite_pipeline" "test" {
+  name       = "tf-acc-steps"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+
+  steps {
+    command {
+      commands = ["make test"]
+    }
+    wait {}
+  }
+}
+`
+
+func testAccPipeline_legacySteps(migrate bool) string {
+	return fmt.Sprintf(`
+resource "buildkite_pipeline" "test" {
+  name       = "tf-acc-legacy-steps"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+
+  migrate_legacy_steps = %t
+
+  env = {
+    DEBUG = "true"
+  }
+
+  step {
+    type              = "script"
+    name              = "Tests"
+    command           = "make test"
+    agent_query_rules = ["queue=default"]
+  }
+
+  step {
+    type = "waiter"
+  }
+
+  step {
+    type                 = "manual"
+    name                 = "Deploy?"
+    branch_configuration = "main"
+  }
+}
+`, migrate)
+}
+
+func testAccPipeline_migratedLegacySteps(configuration string) string {
+	return fmt.Sprintf(`
+resource "buildkite_pipeline" "test" {
+  name       = "tf-acc-legacy-steps"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+
+  configuration = <<EOT
+%sEOT
+}
+`, configuration)
+}
+
+func testAccPipeline_teams(teamIDs string) string {
+	return fmt.Sprintf(`
+resource "buildkite_team" "test" {
+  count = 3
+  name  = "tf-acc-pipeline-team-${count.index}"
+}
+
+resource "buildkite_pipeline" "test_teams" {
+  name       = "tf-acc-teams"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+  team_ids   = %s
+
+  configuration = "steps:\n  - command: echo 'Hello World'\n"
+}
+`, teamIDs)
+}
+
+func testAccPipeline_teamsWithTeamPipeline(teams string) string {
+	return fmt.Sprintf(`
+resource "buildkite_team" "test" {
+  count = 3
+  name  = "tf-acc-pipeline-team-pipeline-${count.index}"
+}
+
+resource "buildkite_pipeline" "test_teams" {
+  name       = "tf-acc-teams-team-pipeline"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+  %s
+
+  configuration = "steps:\n  - command: echo 'Hello World'\n"
+}
+
+resource "buildkite_team_pipeline" "test" {
+  team_id       = buildkite_team.test[1].team_id
+  pipeline_slug = buildkite_pipeline.test_teams.slug
+  access_level  = "READ_ONLY"
+}
+`, teams)
+}
+
+func testAccPipeline_teamAccessLevels(firstAccessLevel string, secondAccessLevel string) string {
+	return fmt.Sprintf(`
+resource "buildkite_team" "test" {
+  count = 2
+  name  = "tf-acc-pipeline-access-team-${count.index}"
+}
+
+resource "buildkite_pipeline" "test_teams" {
+  name       = "tf-acc-team-access-levels"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+
+  team {
+    team_id      = buildkite_team.test[0].team_id
+    access_level = "%s"
+  }
+
+  team {
+    team_id      = buildkite_team.test[1].team_id
+    access_level = "%s"
+  }
+
+  step {
+    type    = "script"
+    command = "echo 'Hello World'"
+  }
+}
+`, firstAccessLevel, secondAccessLevel)
+}
+
+const testAccPipeline_duplicateName = `
+resource "buildkite_pipeline" "test_original" {
+  name       = "tf-acc-duplicate"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+
+  step {
+    type    = "script"
+    name    = "test"
+    command = "echo 'Hello World'"
+  }
+}
+
+resource "buildkite_pipeline" "test_duplicate" {
+  name       = buildkite_pipeline.test_original.name
+  repository = buildkite_pipeline.test_original.repository
+
+  step {
+    type    = "script"
+    name    = "test"
+    command = "echo 'Hello World'"
+  }
+}
+`
+
+func testAccPipeline_cluster(cluster string) string {
+	return fmt.Sprintf(`
+resource "buildkite_cluster" "first" {
+  name = "tf-acc-pipeline-cluster-first"
+}
+
+resource "buildkite_cluster" "second" {
+  name = "tf-acc-pipeline-cluster-second"
+}
+
+resource "buildkite_pipeline" "test_cluster" {
+  name       = "tf-acc-cluster"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+  cluster_id = buildkite_cluster.%s.id
+
+  configuration = "steps:\n  - command: echo 'Hello World'\n"
+}
+`, cluster)
+}
@@ -1,6 +1,8 @@
 package provider
 
 import (
+	"context"
+	"errors"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"log"
 
@@ -16,13 +18,14 @@ var (
 
 func resourceTeam() *schema.Resource {
 	return &schema.Resource{
-		Create: CreateTeam,
-		Read:   ReadTeam,
-		Update: UpdateTeam,
-		Delete: DeleteTeam,
+		Create: withContext(schema.TimeoutCreate, CreateTeam),
+		Read:   withContext(schema.TimeoutRead, ReadTeam),
+		Update: withContext(schema.TimeoutUpdate, UpdateTeam),
+		Delete: withContext(schema.TimeoutDelete, DeleteTeam),
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"slug": {
@@ -71,14 +74,14 @@ func resourceTeam() *schema.Resource {
 	}
 }
 
-func CreateTeam(d *schema.ResourceData, meta interface{}) error {
+func CreateTeam(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] CreatePipeline")
 
 	buildkiteClient := meta.(*client.Client)
 
 	team := prepareTeamRequestPayload(d)
 
-	res, err := buildkiteClient.CreateTeam(team)
+	res, err := buildkiteClient.CreateTeam(ctx, team)
 	if err != nil {
 		return err
 	}
@@ -86,15 +89,16 @@ func CreateTeam(d *schema.ResourceData, meta interface{}) error {
 	return updateTeamFromAPI(d, res)
 }
 
-func ReadTeam(d *schema.ResourceData, meta interface{}) error {
+func ReadTeam(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] ReadPipeline")
 
 	buildkiteClient := meta.(*client.Client)
 	slug := d.Id()
 
-	team, err := buildkiteClient.GetTeam(slug)
+	team, err := buildkiteClient.GetTeam(ctx, slug)
 	if err != nil {
-		if _, ok := err.(*client.NotFound); ok {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
 			d.SetId("")
 			return nil
 		}
@@ -104,14 +108,14 @@ func ReadTeam(d *schema.ResourceData, meta interface{}) error {
 	return updateTeamFromAPI(d, team)
 }
 
-func UpdateTeam(d *schema.ResourceData, meta interface{}) error {
+func UpdateTeam(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] UpdatePipeline")
 
 	buildkiteClient := meta.(*client.Client)
 
 	team := prepareTeamRequestPayload(d)
 
-	res, err := buildkiteClient.UpdateTeam(team)
+	res, err := buildkiteClient.UpdateTeam(ctx, team)
 	if err != nil {
 		return err
 	}
@@ -119,13 +123,13 @@ func UpdateTeam(d *schema.ResourceData, meta interface{}) error {
 	return updateTeamFromAPI(d, res)
 }
 
-func DeleteTeam(d *schema.ResourceData, meta interface{}) error {
+func DeleteTeam(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] DeletePipeline")
 
 	buildkiteClient := meta.(*client.Client)
 	id := d.Get("team_id").(string)
 
-	return buildkiteClient.DeleteTeam(id)
+	return buildkiteClient.DeleteTeam(ctx, id)
 }
 
 func updateTeamFromAPI(d *schema.ResourceData, t *client.Team) error {
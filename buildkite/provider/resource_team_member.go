@@ -1,6 +1,8 @@
 package provider
 
 import (
+	"context"
+	"errors"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"log"
 
@@ -11,18 +13,27 @@ import (
 
 func resourceTeamMember() *schema.Resource {
 	return &schema.Resource{
-		Create: CreateTeamMember,
-		Read:   ReadTeamMember,
-		Update: UpdateTeamMember,
-		Delete: DeleteTeamMember,
+		Create: withContext(schema.TimeoutCreate, CreateTeamMember),
+		Read:   withContext(schema.TimeoutRead, ReadTeamMember),
+		Update: withContext(schema.TimeoutUpdate, UpdateTeamMember),
+		Delete: withContext(schema.TimeoutDelete, DeleteTeamMember),
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"user_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"user_id", "user_email"},
+			},
+			// the user is looked up by email once, when the membership is created
+			"user_email": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
 				ForceNew: true,
 			},
 			"team_id": {
@@ -48,14 +59,21 @@ func resourceTeamMember() *schema.Resource {
 	}
 }
 
-func CreateTeamMember(d *schema.ResourceData, meta interface{}) error {
+func CreateTeamMember(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] CreateTeamMember")
 
 	buildkiteClient := meta.(*client.Client)
 
 	teamMember := prepareTeamMemberRequestPayload(d)
+	if email := d.Get("user_email").(string); email != "" {
+		orgMember, err := buildkiteClient.GetOrganizationMemberByEmail(ctx, email)
+		if err != nil {
+			return err
+		}
+		teamMember.User.Id = orgMember.User.Id
+	}
 
-	res, err := buildkiteClient.CreateTeamMember(teamMember)
+	res, err := buildkiteClient.CreateTeamMember(ctx, teamMember)
 	if err != nil {
 		return err
 	}
@@ -73,7 +91,7 @@ func CreateTeamMember(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	res.Role = teamMember.Role
-	res, err = buildkiteClient.UpdateTeamMember(res)
+	res, err = buildkiteClient.UpdateTeamMember(ctx, res)
 	if err != nil {
 		return err
 	}
@@ -81,15 +99,16 @@ func CreateTeamMember(d *schema.ResourceData, meta interface{}) error {
 	return updateTeamMemberFromAPI(d, res)
 }
 
-func ReadTeamMember(d *schema.ResourceData, meta interface{}) error {
+func ReadTeamMember(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] ReadTeamMember")
 
 	buildkiteClient := meta.(*client.Client)
 	memberId := d.Id()
 
-	teamMember, err := buildkiteClient.GetTeamMember(memberId)
+	teamMember, err := buildkiteClient.GetTeamMember(ctx, memberId)
 	if err != nil {
-		if _, ok := err.(*client.NotFound); ok {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
 			d.SetId("")
 			return nil
 		}
@@ -99,14 +118,14 @@ func ReadTeamMember(d *schema.ResourceData, meta interface{}) error {
 	return updateTeamMemberFromAPI(d, teamMember)
 }
 
-func UpdateTeamMember(d *schema.ResourceData, meta interface{}) error {
+func UpdateTeamMember(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] UpdateTeamMember")
 
 	buildkiteClient := meta.(*client.Client)
 
 	teamMember := prepareTeamMemberRequestPayload(d)
 
-	res, err := buildkiteClient.UpdateTeamMember(teamMember)
+	res, err := buildkiteClient.UpdateTeamMember(ctx, teamMember)
 	if err != nil {
 		return err
 	}
@@ -114,13 +133,13 @@ func UpdateTeamMember(d *schema.ResourceData, meta interface{}) error {
 	return updateTeamMemberFromAPI(d, res)
 }
 
-func DeleteTeamMember(d *schema.ResourceData, meta interface{}) error {
+func DeleteTeamMember(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] DeleteTeamMember")
 
 	buildkiteClient := meta.(*client.Client)
 	id := d.Id()
 
-	return buildkiteClient.DeleteTeamMember(id)
+	return buildkiteClient.DeleteTeamMember(ctx, id)
 }
 
 func updateTeamMemberFromAPI(d *schema.ResourceData, t *client.TeamMember) error {
@@ -1,6 +1,8 @@
 package provider
 
 import (
+	"context"
+	"errors"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"log"
 
@@ -19,13 +21,14 @@ var (
 
 func resourceTeamPipeline() *schema.Resource {
 	return &schema.Resource{
-		Create: CreateTeamPipeline,
-		Read:   ReadTeamPipeline,
-		Update: UpdateTeamPipeline,
-		Delete: DeleteTeamPipeline,
+		Create: withContext(schema.TimeoutCreate, CreateTeamPipeline),
+		Read:   withContext(schema.TimeoutRead, ReadTeamPipeline),
+		Update: withContext(schema.TimeoutUpdate, UpdateTeamPipeline),
+		Delete: withContext(schema.TimeoutDelete, DeleteTeamPipeline),
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: resourceTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"pipeline_slug": {
@@ -60,14 +63,14 @@ func resourceTeamPipeline() *schema.Resource {
 	}
 }
 
-func CreateTeamPipeline(d *schema.ResourceData, meta interface{}) error {
+func CreateTeamPipeline(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] CreateTeamPipeline")
 
 	buildkiteClient := meta.(*client.Client)
 
 	teamPipeline := prepareTeamPipelineRequestPayload(d)
 
-	res, err := buildkiteClient.CreateTeamPipeline(teamPipeline)
+	res, err := buildkiteClient.CreateTeamPipeline(ctx, teamPipeline)
 	if err != nil {
 		return err
 	}
@@ -85,7 +88,7 @@ func CreateTeamPipeline(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	res.AccessLevel = teamPipeline.AccessLevel
-	res, err = buildkiteClient.UpdateTeamPipeline(res)
+	res, err = buildkiteClient.UpdateTeamPipeline(ctx, res)
 	if err != nil {
 		return err
 	}
@@ -93,15 +96,16 @@ func CreateTeamPipeline(d *schema.ResourceData, meta interface{}) error {
 	return updateTeamPipelineFromAPI(d, res)
 }
 
-func ReadTeamPipeline(d *schema.ResourceData, meta interface{}) error {
+func ReadTeamPipeline(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] ReadTeamPipeline")
 
 	buildkiteClient := meta.(*client.Client)
 	memberId := d.Id()
 
-	teamPipeline, err := buildkiteClient.GetTeamPipeline(memberId)
+	teamPipeline, err := buildkiteClient.GetTeamPipeline(ctx, memberId)
 	if err != nil {
-		if _, ok := err.(*client.NotFound); ok {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
 			d.SetId("")
 			return nil
 		}
@@ -111,14 +115,14 @@ func ReadTeamPipeline(d *schema.ResourceData, meta interface{}) error {
 	return updateTeamPipelineFromAPI(d, teamPipeline)
 }
 
-func UpdateTeamPipeline(d *schema.ResourceData, meta interface{}) error {
+func UpdateTeamPipeline(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] UpdateTeamPipeline")
 
 	buildkiteClient := meta.(*client.Client)
 
 	teamPipeline := prepareTeamPipelineRequestPayload(d)
 
-	res, err := buildkiteClient.UpdateTeamPipeline(teamPipeline)
+	res, err := buildkiteClient.UpdateTeamPipeline(ctx, teamPipeline)
 	if err != nil {
 		return err
 	}
@@ -126,13 +130,13 @@ func UpdateTeamPipeline(d *schema.ResourceData, meta interface{}) error {
 	return updateTeamPipelineFromAPI(d, res)
 }
 
-func DeleteTeamPipeline(d *schema.ResourceData, meta interface{}) error {
+func DeleteTeamPipeline(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[TRACE] DeleteTeamPipeline")
 
 	buildkiteClient := meta.(*client.Client)
 	id := d.Id()
 
-	return buildkiteClient.DeleteTeamPipeline(id)
+	return buildkiteClient.DeleteTeamPipeline(ctx, id)
 }
 
 func updateTeamPipelineFromAPI(d *schema.ResourceData, t *client.TeamPipeline) error {
@@ -0,0 +1,238 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+
+	buildkiteClient "github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+func TestAccTeam_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteTeamDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccTeam_basic("tf-acc-team", "VISIBLE"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkiteTeamExists("buildkite_team.test"),
+					resource.TestCheckResourceAttr("buildkite_team.test", "id", "tf-acc-team"),
+					resource.TestCheckResourceAttr("buildkite_team.test", "slug", "tf-acc-team"),
+					resource.TestCheckResourceAttr("buildkite_team.test", "privacy", "VISIBLE"),
+					resource.TestCheckResourceAttr("buildkite_team.test", "default_member_role", "MEMBER"),
+					resource.TestCheckResourceAttr("buildkite_team.test", "is_default_team", "false"),
+					resource.TestCheckResourceAttrSet("buildkite_team.test", "team_id"),
+					resource.TestCheckResourceAttrSet("buildkite_team.test", "uuid"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccTeam_basic("tf-acc-team", "SECRET"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_team.test", "privacy", "SECRET"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      "buildkite_team.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccTeam_pipeline(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteTeamDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccTeam_pipeline("BUILD_AND_READ"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_team_pipeline.test", "access_level", "BUILD_AND_READ"),
+					resource.TestCheckResourceAttr("buildkite_team_pipeline.test", "pipeline_slug", "tf-acc-team-pipeline"),
+					resource.TestCheckResourceAttrPair("buildkite_team_pipeline.test", "team_id", "buildkite_team.test", "team_id"),
+					resource.TestCheckResourceAttrSet("buildkite_team_pipeline.test", "pipeline_id"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccTeam_pipeline("READ_ONLY"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_team_pipeline.test", "access_level", "READ_ONLY"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccTeam_member(t *testing.T) {
+	if testAccServer == nil {
+		t.Skip("team members need an existing user, which only the fake Buildkite API can provide")
+	}
+	_, userID := testAccServer.AddOrganizationMember("Terraform Acceptance", "tf-acc@example.com", "MEMBER")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteTeamDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccTeam_member(userID, "MAINTAINER"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_team_member.test", "role", "MAINTAINER"),
+					resource.TestCheckResourceAttr("buildkite_team_member.test", "user_id", userID),
+					resource.TestCheckResourceAttrPair("buildkite_team_member.test", "team_id", "buildkite_team.test", "team_id"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccTeam_member(userID, "MEMBER"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_team_member.test", "role", "MEMBER"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccTeam_memberByEmail(t *testing.T) {
+	if testAccServer == nil {
+		t.Skip("team members need an existing user, which only the fake Buildkite API can provide")
+	}
+	_, userID := testAccServer.AddOrganizationMember("Terraform Email", "tf-acc-email@example.com", "MEMBER")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteTeamDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccTeam_memberByEmail("tf-acc-nobody@example.com"),
+				ExpectError: regexp.MustCompile("no member of organization .* has the email tf-acc-nobody@example.com"),
+			},
+			resource.TestStep{
+				Config: testAccTeam_memberByEmail("TF-ACC-EMAIL@example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_team_member.test", "user_id", userID),
+					resource.TestCheckResourceAttr("buildkite_team_member.test", "user_email", "TF-ACC-EMAIL@example.com"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBuildkiteTeamExists(id string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found: %s", id)
+		}
+
+		res, err := client.GetTeam(context.Background(), rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		if res.Slug != rs.Primary.ID {
+			return fmt.Errorf("Team not found")
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckBuildkiteTeamDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "buildkite_team" {
+			continue
+		}
+		if !strings.HasPrefix(rs.Primary.Attributes["name"], "tf-acc-") {
+			continue
+		}
+
+		_, err := client.GetTeam(context.Background(), rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Team still exists")
+		}
+
+		var notFound *buildkiteClient.NotFound
+		if !errors.As(err, &notFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testAccTeam_basic(name string, privacy string) string {
+	return fmt.Sprintf(`
+resource "buildkite_team" "test" {
+  name    = "%s"
+  privacy = "%s"
+}
+`, name, privacy)
+}
+
+func testAccTeam_pipeline(accessLevel string) string {
+	return fmt.Sprintf(`
+resource "buildkite_team" "test" {
+  name = "tf-acc-team-with-pipeline"
+}
+
+resource "buildkite_pipeline" "test" {
+  name       = "tf-acc-team-pipeline"
+  repository = "git@github.com:saymedia/terraform-provider-buildkite.git"
+
+  configuration = "steps:\n  - command: echo 'Hello World'\n"
+
+  # access granted by buildkite_team_pipeline shows up in team_ids
+  lifecycle {
+    ignore_changes = [team_ids]
+  }
+}
+
+resource "buildkite_team_pipeline" "test" {
+  team_id       = buildkite_team.test.team_id
+  pipeline_slug = buildkite_pipeline.test.slug
+  access_level  = "%s"
+}
+`, accessLevel)
+}
+
+func testAccTeam_memberByEmail(email string) string {
+	return fmt.Sprintf(`
+resource "buildkite_team" "test" {
+  name = "tf-acc-team-with-member-by-email"
+}
+
+resource "buildkite_team_member" "test" {
+  team_id    = buildkite_team.test.team_id
+  user_email = "%s"
+}
+`, email)
+}
+
+func testAccTeam_member(userID string, role string) string {
+	return fmt.Sprintf(`
+resource "buildkite_team" "test" {
+  name = "tf-acc-team-with-member"
+}
+
+resource "buildkite_team_member" "test" {
+  team_id = buildkite_team.test.team_id
+  user_id = "%s"
+  role    = "%s"
+}
+`, userID, role)
+}
@@ -0,0 +1,236 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	"github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+func resourceTestSuite() *schema.Resource {
+	return &schema.Resource{
+		Create: withContext(schema.TimeoutCreate, CreateTestSuite),
+		Read:   withContext(schema.TimeoutRead, ReadTestSuite),
+		Update: withContext(schema.TimeoutUpdate, UpdateTestSuite),
+		Delete: withContext(schema.TimeoutDelete, DeleteTestSuite),
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Timeouts: resourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"default_branch": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			// the owner is not part of the suite, it is the team given MANAGE_AND_READ access when the suite is
+			// created, so it is read back from the teams with that access
+			"team_owner_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			// team_owner_access_id is the team access this resource granted the owner, it is empty if the owner
+			// already had access, e.g. through buildkite_test_suite_team, and then kept when the owner changes
+			"team_owner_access_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"api_token": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"slug": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"web_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateTestSuite(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] CreateTestSuite")
+
+	buildkiteClient := meta.(*client.Client)
+
+	res, err := buildkiteClient.CreateTestSuite(ctx, prepareTestSuiteRequestPayload(d))
+	if err != nil {
+		return err
+	}
+
+	// the API token is only revealed once, so it is kept in the state from now on
+	d.Set("api_token", res.APIToken)
+
+	teamSuites, err := buildkiteClient.GetTestSuiteTeams(ctx, res.Id)
+	if err != nil {
+		return err
+	}
+	for _, teamSuite := range teamSuites {
+		if teamSuite.Team.Id == d.Get("team_owner_id").(string) {
+			d.Set("team_owner_access_id", teamSuite.Id)
+		}
+	}
+
+	return updateTestSuiteFromAPI(d, res, teamSuites)
+}
+
+func ReadTestSuite(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] ReadTestSuite")
+
+	buildkiteClient := meta.(*client.Client)
+
+	suite, err := buildkiteClient.GetTestSuite(ctx, d.Id())
+	if err != nil {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	teamSuites, err := buildkiteClient.GetTestSuiteTeams(ctx, suite.Id)
+	if err != nil {
+		return err
+	}
+
+	return updateTestSuiteFromAPI(d, suite, teamSuites)
+}
+
+func UpdateTestSuite(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] UpdateTestSuite")
+
+	buildkiteClient := meta.(*client.Client)
+
+	suite := prepareTestSuiteRequestPayload(d)
+	suite.Id = d.Id()
+
+	if d.HasChange("team_owner_id") {
+		granted, err := transferTestSuiteOwnership(ctx, buildkiteClient, suite.Id, d.Get("team_owner_access_id").(string), suite.TeamOwnerId)
+		if err != nil {
+			return err
+		}
+		d.Set("team_owner_access_id", granted)
+	}
+
+	res, err := buildkiteClient.UpdateTestSuite(ctx, suite)
+	if err != nil {
+		return err
+	}
+
+	teamSuites, err := buildkiteClient.GetTestSuiteTeams(ctx, res.Id)
+	if err != nil {
+		return err
+	}
+
+	return updateTestSuiteFromAPI(d, res, teamSuites)
+}
+
+func DeleteTestSuite(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] DeleteTestSuite")
+
+	buildkiteClient := meta.(*client.Client)
+
+	return buildkiteClient.DeleteTestSuite(ctx, d.Id())
+}
+
+// transferTestSuiteOwnership gives the new owner MANAGE_AND_READ access before the access this resource granted the
+// previous owner is removed, so that the suite is never left without a team managing it. Access which the previous
+// owner had been given otherwise, e.g. by buildkite_test_suite_team, is kept. It returns the ID of the access granted
+// to the new owner, or an empty string if the new owner already had access.
+func transferTestSuiteOwnership(ctx context.Context, c *client.Client, suiteId string, grantedId string, newTeamId string) (string, error) {
+	teamSuites, err := c.GetTestSuiteTeams(ctx, suiteId)
+	if err != nil {
+		return "", err
+	}
+
+	var granted, next *client.TeamSuite
+	for i := range teamSuites {
+		switch {
+		case teamSuites[i].Team.Id == newTeamId:
+			next = &teamSuites[i]
+		case grantedId != "" && teamSuites[i].Id == grantedId:
+			granted = &teamSuites[i]
+		}
+	}
+
+	grantedNext := ""
+	switch {
+	case next == nil:
+		next = &client.TeamSuite{AccessLevel: client.TeamSuiteAccessManage}
+		next.Team.Id = newTeamId
+		next.Suite.Id = suiteId
+		created, err := c.CreateTeamSuite(ctx, next)
+		if err != nil {
+			return "", err
+		}
+		grantedNext = created.Id
+	case next.AccessLevel != client.TeamSuiteAccessManage:
+		next.AccessLevel = client.TeamSuiteAccessManage
+		if _, err := c.UpdateTeamSuite(ctx, next); err != nil {
+			return "", err
+		}
+	}
+
+	if granted != nil {
+		if err := c.DeleteTeamSuite(ctx, granted.Id); err != nil {
+			return "", err
+		}
+	}
+	return grantedNext, nil
+}
+
+func updateTestSuiteFromAPI(d *schema.ResourceData, s *client.TestSuite, teamSuites []client.TeamSuite) error {
+	d.SetId(s.Id)
+	log.Printf("[INFO] buildkite: test suite ID: %s", d.Id())
+
+	d.Set("uuid", s.UUID)
+	d.Set("slug", s.Slug)
+	d.Set("name", s.Name)
+	d.Set("default_branch", s.DefaultBranch)
+	d.Set("web_url", s.URL)
+	d.Set("team_owner_id", testSuiteOwner(d.Get("team_owner_id").(string), teamSuites))
+
+	return nil
+}
+
+// testSuiteOwner returns the team which owns the suite, i.e. has MANAGE_AND_READ access to it. The current owner is
+// kept if other teams have been given that access too.
+func testSuiteOwner(current string, teamSuites []client.TeamSuite) string {
+	owner := ""
+	for _, teamSuite := range teamSuites {
+		if teamSuite.AccessLevel != client.TeamSuiteAccessManage {
+			continue
+		}
+		if teamSuite.Team.Id == current {
+			return current
+		}
+		if owner == "" {
+			owner = teamSuite.Team.Id
+		}
+	}
+	return owner
+}
+
+func prepareTestSuiteRequestPayload(d *schema.ResourceData) *client.TestSuite {
+	return &client.TestSuite{
+		Name:          d.Get("name").(string),
+		DefaultBranch: d.Get("default_branch").(string),
+		TeamOwnerId:   d.Get("team_owner_id").(string),
+	}
+}
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+
+	"github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+var (
+	ValidTeamSuiteAccessLevels = []string{
+		client.TeamSuiteAccessReadOnly,
+		client.TeamSuiteAccessManage,
+	}
+)
+
+func resourceTestSuiteTeam() *schema.Resource {
+	return &schema.Resource{
+		Create: withContext(schema.TimeoutCreate, CreateTestSuiteTeam),
+		Read:   withContext(schema.TimeoutRead, ReadTestSuiteTeam),
+		Update: withContext(schema.TimeoutUpdate, UpdateTestSuiteTeam),
+		Delete: withContext(schema.TimeoutDelete, DeleteTestSuiteTeam),
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Timeouts: resourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"test_suite_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"team_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"access_level": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      client.TeamSuiteAccessReadOnly,
+				ValidateFunc: validation.StringInSlice(ValidTeamSuiteAccessLevels, false),
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateTestSuiteTeam(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] CreateTestSuiteTeam")
+
+	buildkiteClient := meta.(*client.Client)
+
+	res, err := buildkiteClient.CreateTeamSuite(ctx, prepareTestSuiteTeamRequestPayload(d))
+	if err != nil {
+		return err
+	}
+
+	return updateTestSuiteTeamFromAPI(d, res)
+}
+
+func ReadTestSuiteTeam(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] ReadTestSuiteTeam")
+
+	buildkiteClient := meta.(*client.Client)
+
+	teamSuite, err := buildkiteClient.GetTeamSuite(ctx, d.Id())
+	if err != nil {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	return updateTestSuiteTeamFromAPI(d, teamSuite)
+}
+
+func UpdateTestSuiteTeam(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] UpdateTestSuiteTeam")
+
+	buildkiteClient := meta.(*client.Client)
+
+	res, err := buildkiteClient.UpdateTeamSuite(ctx, prepareTestSuiteTeamRequestPayload(d))
+	if err != nil {
+		return err
+	}
+
+	return updateTestSuiteTeamFromAPI(d, res)
+}
+
+func DeleteTestSuiteTeam(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[TRACE] DeleteTestSuiteTeam")
+
+	buildkiteClient := meta.(*client.Client)
+
+	return buildkiteClient.DeleteTeamSuite(ctx, d.Id())
+}
+
+func updateTestSuiteTeamFromAPI(d *schema.ResourceData, t *client.TeamSuite) error {
+	d.SetId(t.Id)
+	log.Printf("[INFO] buildkite: test suite team ID: %s", d.Id())
+
+	d.Set("uuid", t.UUID)
+	d.Set("access_level", t.AccessLevel)
+	d.Set("created_at", t.CreatedAt)
+	d.Set("team_id", t.Team.Id)
+	d.Set("test_suite_id", t.Suite.Id)
+
+	return nil
+}
+
+func prepareTestSuiteTeamRequestPayload(d *schema.ResourceData) *client.TeamSuite {
+	req := &client.TeamSuite{}
+
+	req.Id = d.Id()
+	req.AccessLevel = d.Get("access_level").(string)
+	req.Team.Id = d.Get("team_id").(string)
+	req.Suite.Id = d.Get("test_suite_id").(string)
+
+	return req
+}
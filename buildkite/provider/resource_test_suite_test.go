@@ -0,0 +1,266 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+
+	buildkiteClient "github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+func TestAccTestSuite_basic(t *testing.T) {
+	var suiteID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteTestSuiteDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccTestSuite_basic("main", "backend"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBuildkiteTestSuiteExists("buildkite_test_suite.test", &suiteID),
+					resource.TestCheckResourceAttr("buildkite_test_suite.test", "name", "tf-acc-test-suite"),
+					resource.TestCheckResourceAttr("buildkite_test_suite.test", "slug", "tf-acc-test-suite"),
+					resource.TestCheckResourceAttr("buildkite_test_suite.test", "default_branch", "main"),
+					resource.TestCheckResourceAttrPair("buildkite_test_suite.test", "team_owner_id", "buildkite_team.backend", "team_id"),
+					resource.TestCheckResourceAttrSet("buildkite_test_suite.test", "api_token"),
+					resource.TestCheckResourceAttrSet("buildkite_test_suite.test", "uuid"),
+					resource.TestCheckResourceAttrSet("buildkite_test_suite.test", "web_url"),
+					testAccCheckBuildkiteTestSuiteTeamAccess("buildkite_test_suite.test", "buildkite_team.backend", "MANAGE_AND_READ"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccTestSuite_basic("develop", "frontend"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_test_suite.test", "default_branch", "develop"),
+					resource.TestCheckResourceAttrPair("buildkite_test_suite.test", "team_owner_id", "buildkite_team.frontend", "team_id"),
+					// changing the owner must not replace the suite, that would change its API token
+					resource.TestCheckResourceAttrPtr("buildkite_test_suite.test", "id", &suiteID),
+					testAccCheckBuildkiteTestSuiteTeamAccess("buildkite_test_suite.test", "buildkite_team.frontend", "MANAGE_AND_READ"),
+					testAccCheckBuildkiteTestSuiteTeamAccess("buildkite_test_suite.test", "buildkite_team.backend", ""),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      "buildkite_test_suite.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// the API only reveals the token when the suite is created, and an imported suite doesn't know which
+				// team access it granted
+				ImportStateVerifyIgnore: []string{"api_token", "team_owner_access_id"},
+			},
+		},
+	})
+}
+
+func TestAccTestSuite_team(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteTestSuiteDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccTestSuite_team("READ_ONLY"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_test_suite_team.test", "access_level", "READ_ONLY"),
+					resource.TestCheckResourceAttrPair("buildkite_test_suite_team.test", "test_suite_id", "buildkite_test_suite.test", "id"),
+					resource.TestCheckResourceAttrPair("buildkite_test_suite_team.test", "team_id", "buildkite_team.frontend", "team_id"),
+					resource.TestCheckResourceAttrSet("buildkite_test_suite_team.test", "uuid"),
+					testAccCheckBuildkiteTestSuiteTeamAccess("buildkite_test_suite.test", "buildkite_team.frontend", "READ_ONLY"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccTestSuite_team("MANAGE_AND_READ"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_test_suite_team.test", "access_level", "MANAGE_AND_READ"),
+					testAccCheckBuildkiteTestSuiteTeamAccess("buildkite_test_suite.test", "buildkite_team.frontend", "MANAGE_AND_READ"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      "buildkite_test_suite_team.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccTestSuite_ownerWithTeamAccess(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBuildkiteTestSuiteDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccTestSuite_ownerWithTeamAccess("backend"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("buildkite_test_suite.test", "team_owner_id", "buildkite_team.backend", "team_id"),
+					testAccCheckBuildkiteTestSuiteTeamAccess("buildkite_test_suite.test", "buildkite_team.backend", "MANAGE_AND_READ"),
+					testAccCheckBuildkiteTestSuiteTeamAccess("buildkite_test_suite.test", "buildkite_team.frontend", "MANAGE_AND_READ"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccTestSuite_ownerWithTeamAccess("frontend"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("buildkite_test_suite.test", "team_owner_id", "buildkite_team.frontend", "team_id"),
+					resource.TestCheckResourceAttr("buildkite_test_suite.test", "team_owner_access_id", ""),
+					testAccCheckBuildkiteTestSuiteTeamAccess("buildkite_test_suite.test", "buildkite_team.backend", ""),
+					testAccCheckBuildkiteTestSuiteTeamAccess("buildkite_test_suite.test", "buildkite_team.frontend", "MANAGE_AND_READ"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccTestSuite_ownerWithTeamAccess("backend"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("buildkite_test_suite.test", "team_owner_id", "buildkite_team.backend", "team_id"),
+					testAccCheckBuildkiteTestSuiteTeamAccess("buildkite_test_suite.test", "buildkite_team.backend", "MANAGE_AND_READ"),
+					// the access of the previous owner is managed by buildkite_test_suite_team, so it is kept
+					testAccCheckBuildkiteTestSuiteTeamAccess("buildkite_test_suite.test", "buildkite_team.frontend", "MANAGE_AND_READ"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBuildkiteTestSuiteExists(id string, suiteID *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+		rs, ok := s.RootModule().Resources[id]
+		if !ok {
+			return fmt.Errorf("Not found: %s", id)
+		}
+
+		if _, err := client.GetTestSuite(context.Background(), rs.Primary.ID); err != nil {
+			return err
+		}
+
+		*suiteID = rs.Primary.ID
+		return nil
+	}
+}
+
+// testAccCheckBuildkiteTestSuiteTeamAccess checks the access level the team has to the suite, an empty access level
+// means the team has no access
+func testAccCheckBuildkiteTestSuiteTeamAccess(suiteID string, teamID string, accessLevel string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+		suite, ok := s.RootModule().Resources[suiteID]
+		if !ok {
+			return fmt.Errorf("Not found: %s", suiteID)
+		}
+		team, ok := s.RootModule().Resources[teamID]
+		if !ok {
+			return fmt.Errorf("Not found: %s", teamID)
+		}
+
+		teamSuites, err := client.GetTestSuiteTeams(context.Background(), suite.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		actual := ""
+		for _, teamSuite := range teamSuites {
+			if teamSuite.Team.Id == team.Primary.Attributes["team_id"] {
+				actual = teamSuite.AccessLevel
+			}
+		}
+		if actual != accessLevel {
+			return fmt.Errorf("expected %s to have access %q to the suite, got %q", teamID, accessLevel, actual)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckBuildkiteTestSuiteDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*buildkiteClient.Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "buildkite_test_suite" {
+			continue
+		}
+
+		_, err := client.GetTestSuite(context.Background(), rs.Primary.ID)
+		if err == nil {
+			return fmt.Errorf("Test suite still exists")
+		}
+
+		var notFound *buildkiteClient.NotFound
+		if !errors.As(err, &notFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testAccTestSuite_basic(defaultBranch string, owner string) string {
+	return fmt.Sprintf(`
+resource "buildkite_team" "backend" {
+  name = "tf-acc-test-suite-backend"
+}
+
+resource "buildkite_team" "frontend" {
+  name = "tf-acc-test-suite-frontend"
+}
+
+resource "buildkite_test_suite" "test" {
+  name           = "tf-acc-test-suite"
+  default_branch = "%s"
+  team_owner_id  = buildkite_team.%s.team_id
+}
+`, defaultBranch, owner)
+}
+
+func testAccTestSuite_team(accessLevel string) string {
+	return fmt.Sprintf(`
+resource "buildkite_team" "backend" {
+  name = "tf-acc-test-suite-team-backend"
+}
+
+resource "buildkite_team" "frontend" {
+  name = "tf-acc-test-suite-team-frontend"
+}
+
+resource "buildkite_test_suite" "test" {
+  name           = "tf-acc-test-suite-team"
+  default_branch = "main"
+  team_owner_id  = buildkite_team.backend.team_id
+}
+
+resource "buildkite_test_suite_team" "test" {
+  test_suite_id = buildkite_test_suite.test.id
+  team_id       = buildkite_team.frontend.team_id
+  access_level  = "%s"
+}
+`, accessLevel)
+}
+
+func testAccTestSuite_ownerWithTeamAccess(owner string) string {
+	return fmt.Sprintf(`
+resource "buildkite_team" "backend" {
+  name = "tf-acc-test-suite-owner-backend"
+}
+
+resource "buildkite_team" "frontend" {
+  name = "tf-acc-test-suite-owner-frontend"
+}
+
+resource "buildkite_test_suite" "test" {
+  name           = "tf-acc-test-suite-owner"
+  default_branch = "main"
+  team_owner_id  = buildkite_team.%s.team_id
+}
+
+resource "buildkite_test_suite_team" "test" {
+  test_suite_id = buildkite_test_suite.test.id
+  team_id       = buildkite_team.frontend.team_id
+  access_level  = "MANAGE_AND_READ"
+}
+`, owner)
+}
@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	"github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+// resourceScopes are the API token scopes each resource needs to manage its objects
+var resourceScopes = map[string][]string{
+	"buildkite_agent_token":             {client.ScopeGraphQL},
+	"buildkite_cluster":                 {client.ScopeReadClusters, client.ScopeWriteClusters},
+	"buildkite_cluster_agent_token":     {client.ScopeReadClusters, client.ScopeWriteClusters},
+	"buildkite_cluster_queue":           {client.ScopeReadClusters, client.ScopeWriteClusters},
+	"buildkite_org_member":              {client.ScopeGraphQL},
+	"buildkite_organization_invitation": {client.ScopeGraphQL},
+	"buildkite_pipeline":                {client.ScopeReadPipelines, client.ScopeWritePipelines, client.ScopeGraphQL},
+	"buildkite_pipeline_schedule":       {client.ScopeGraphQL},
+	"buildkite_pipeline_template":       {client.ScopeGraphQL},
+	"buildkite_team":                    {client.ScopeGraphQL},
+	"buildkite_team_member":             {client.ScopeGraphQL},
+	"buildkite_team_pipeline":           {client.ScopeGraphQL},
+	"buildkite_test_suite":              {client.ScopeGraphQL},
+	"buildkite_test_suite_team":         {client.ScopeGraphQL},
+}
+
+// requiredScopes are the API token scopes the provider can't work without, the organization is looked up through
+// GraphQL. The scopes only some resources need are checked when those resources are used, see requireResourceScopes.
+var requiredScopes = []string{client.ScopeGraphQL}
+
+// apiAccessTokensURL is where the scopes of API tokens are granted
+const apiAccessTokensURL = "https://buildkite.com/user/api-access-tokens"
+
+// validateToken checks that the API token is valid, has been granted the scopes the provider needs and can access the
+// organization, so that a misconfigured token fails before anything is changed. Scopes which only some resources need
+// are reported as a warning, as the token may deliberately not be used with those resources.
+func validateToken(ctx context.Context, c *client.Client, orgSlug string) error {
+	token, err := c.GetAccessToken(ctx)
+	if err != nil {
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) && apiErr.IsAuthError() {
+			return fmt.Errorf("the Buildkite API token is invalid or has been revoked: %v", err)
+		}
+		return fmt.Errorf("failed to validate the Buildkite API token: %v", err)
+	}
+
+	if err := missingScopesError(token); err != nil {
+		return err
+	}
+	if warning := missingScopesWarning(token); warning != "" {
+		log.Printf("[WARN] %s", warning)
+	}
+
+	if _, err := c.GetOrganizationId(ctx, orgSlug); err != nil {
+		var notFound *client.NotFound
+		if errors.As(err, &notFound) {
+			return fmt.Errorf("the Buildkite organization %q doesn't exist or the API token has no access to it", orgSlug)
+		}
+		return fmt.Errorf("failed to look up the Buildkite organization %q: %v", orgSlug, err)
+	}
+
+	return nil
+}
+
+// missingScopesError fails if the token lacks any of the scopes the provider can't work without
+func missingScopesError(token *client.AccessToken) error {
+	var missing []string
+	for _, scope := range requiredScopes {
+		if !token.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("the Buildkite API token is missing the %s scopes the provider needs, grant them to the token at %s",
+		strings.Join(missing, ", "), apiAccessTokensURL)
+}
+
+// missingScopesWarning names the resources which will fail to manage their objects because the token lacks scopes
+// they need, or returns an empty string if the token has all of them
+func missingScopesWarning(token *client.AccessToken) string {
+	missing := map[string][]string{}
+	for resourceName, scopes := range resourceScopes {
+		for _, scope := range scopes {
+			if !token.HasScope(scope) {
+				missing[scope] = append(missing[scope], resourceName)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return ""
+	}
+
+	scopes := make([]string, 0, len(missing))
+	for scope := range missing {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+
+	var b strings.Builder
+	b.WriteString("the Buildkite API token is missing scopes some resources need, they will fail to manage their objects:")
+	for _, scope := range scopes {
+		sort.Strings(missing[scope])
+		fmt.Fprintf(&b, "\n  %s, needed by %s", scope, strings.Join(missing[scope], ", "))
+	}
+
+	return b.String()
+}
+
+// requireResourceScopes makes the operations of the resource fail with an error naming the scopes the API token
+// lacks, instead of the bare 403 of the first request needing one of them. Once the scopes of the token are known,
+// creating, updating and deleting fail before any request is made.
+func requireResourceScopes(name string, r *schema.Resource) {
+	r.Create = withResourceScopes(name, true, r.Create)
+	r.Read = withResourceScopes(name, false, r.Read)
+	if r.Update != nil {
+		r.Update = withResourceScopes(name, true, r.Update)
+	}
+	r.Delete = withResourceScopes(name, true, r.Delete)
+}
+
+// resourceFunc is any of the CRUD functions of a resource
+type resourceFunc = func(*schema.ResourceData, interface{}) error
+
+func withResourceScopes(name string, checkFirst bool, f resourceFunc) resourceFunc {
+	return func(d *schema.ResourceData, meta interface{}) error {
+		c := meta.(*client.Client)
+		if checkFirst {
+			if missing, known := missingResourceScopes(c, name); known && len(missing) > 0 {
+				return fmt.Errorf("%s needs the %s scopes, which the Buildkite API token lacks, grant them to the token at %s",
+					name, strings.Join(missing, ", "), apiAccessTokensURL)
+			}
+		}
+
+		err := f(d, meta)
+		var apiErr *client.APIError
+		if err == nil || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+			return err
+		}
+		missing, known := missingResourceScopes(c, name)
+		if !known {
+			return fmt.Errorf("%v\n%s needs the %s scopes, check that the Buildkite API token has been granted them at %s",
+				err, name, strings.Join(resourceScopes[name], ", "), apiAccessTokensURL)
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%v\n%s needs the %s scopes, which the Buildkite API token lacks, grant them to the token at %s",
+				err, name, strings.Join(missing, ", "), apiAccessTokensURL)
+		}
+		return err
+	}
+}
+
+// missingResourceScopes returns the scopes the resource needs which the API token lacks, known is false if the scopes
+// of the token aren't known because it wasn't validated
+func missingResourceScopes(c *client.Client, name string) (missing []string, known bool) {
+	granted, known := c.GrantedScopes()
+	if !known {
+		return nil, false
+	}
+	token := &client.AccessToken{Scopes: granted}
+	for _, scope := range resourceScopes[name] {
+		if !token.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing, true
+}
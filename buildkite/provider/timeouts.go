@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	"github.com/saymedia/terraform-buildkite/buildkite/client"
+)
+
+const defaultTimeout = 5 * time.Minute
+
+// contextFunc is a CRUD function which is given a context bounded by the operation's timeout
+type contextFunc func(ctx context.Context, d *schema.ResourceData, meta interface{}) error
+
+// resourceTimeouts allows every operation of a resource to be limited with a timeouts block
+func resourceTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create:  schema.DefaultTimeout(defaultTimeout),
+		Read:    schema.DefaultTimeout(defaultTimeout),
+		Update:  schema.DefaultTimeout(defaultTimeout),
+		Delete:  schema.DefaultTimeout(defaultTimeout),
+		Default: schema.DefaultTimeout(defaultTimeout),
+	}
+}
+
+// withContext runs f with a context which is cancelled once the timeout configured for the operation has passed
+// or Terraform asks the provider to stop, e.g. on Ctrl-C
+func withContext(timeoutKey string, f contextFunc) func(*schema.ResourceData, interface{}) error {
+	return func(d *schema.ResourceData, meta interface{}) error {
+		ctx, cancel := context.WithTimeout(meta.(*client.Client).StopContext(), d.Timeout(timeoutKey))
+		defer cancel()
+
+		return f(ctx, d, meta)
+	}
+}
@@ -0,0 +1,66 @@
+package testserver
+
+import (
+	"fmt"
+)
+
+type agentToken struct {
+	ID          string
+	UUID        string
+	Description string
+	Token       string
+	RevokedAt   string
+}
+
+func agentTokenNode(t *agentToken) map[string]interface{} {
+	var revokedAt interface{}
+	if t.RevokedAt != "" {
+		revokedAt = t.RevokedAt
+	}
+	return map[string]interface{}{
+		"id":          t.ID,
+		"uuid":        t.UUID,
+		"description": t.Description,
+		"revokedAt":   revokedAt,
+	}
+}
+
+func (s *Server) resolveAgentTokenCreate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	if input["organizationID"] != s.orgID {
+		return nil, fmt.Errorf("No organization found with id %v", input["organizationID"])
+	}
+
+	uuid := newUUID()
+	t := &agentToken{
+		ID:    graphQLID("AgentToken", uuid),
+		UUID:  uuid,
+		Token: newUUID(),
+	}
+	t.Description, _ = input["description"].(string)
+	s.agentTokens[t.ID] = t
+
+	return map[string]interface{}{
+		"agentTokenEdge": map[string]interface{}{
+			"node": agentTokenNode(t),
+		},
+		"tokenValue": t.Token,
+	}, nil
+}
+
+func (s *Server) resolveAgentTokenRevoke(args map[string]interface{}) (interface{}, error) {
+	id, _ := inputOf(args)["id"].(string)
+	t, ok := s.agentTokens[id]
+	if !ok {
+		return nil, fmt.Errorf("No agent token found with id %s", id)
+	}
+	if t.RevokedAt != "" {
+		return nil, fmt.Errorf("Agent token has already been revoked")
+	}
+
+	t.RevokedAt = now()
+
+	return map[string]interface{}{
+		"agentToken": agentTokenNode(t),
+	}, nil
+}
@@ -0,0 +1,378 @@
+package testserver
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type cluster struct {
+	ID             string
+	UUID           string
+	Name           string
+	Description    string
+	Emoji          string
+	Color          string
+	DefaultQueueID string
+	CreatedAt      string
+}
+
+type clusterQueue struct {
+	ID             string
+	UUID           string
+	ClusterUUID    string
+	Key            string
+	Description    string
+	DispatchPaused bool
+	InstanceShape  string
+	AgentImageRef  string
+	CreatedAt      string
+}
+
+type clusterAgentToken struct {
+	ID                 string
+	UUID               string
+	ClusterUUID        string
+	Description        string
+	AllowedIPAddresses string
+	Token              string
+	CreatedAt          string
+}
+
+// serveClusters serves /v2/organizations/{org}/clusters, path is the remainder of the URL path
+func (s *Server) serveClusters(w http.ResponseWriter, r *http.Request, path []string) {
+	if len(path) >= 2 && (path[1] == "queues" || path[1] == "tokens") {
+		c, ok := s.clusters[path[0]]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+			return
+		}
+		if path[1] == "queues" {
+			s.serveClusterQueues(w, r, c, path[2:])
+		} else {
+			s.serveClusterAgentTokens(w, r, c, path[2:])
+		}
+		return
+	}
+
+	switch {
+	case len(path) == 0 && r.Method == http.MethodPost:
+		s.createCluster(w, r)
+	case len(path) == 1 && r.Method == http.MethodGet:
+		s.getCluster(w, path[0])
+	case len(path) == 1 && r.Method == http.MethodPatch:
+		s.updateCluster(w, r, path[0])
+	case len(path) == 1 && r.Method == http.MethodDelete:
+		s.deleteCluster(w, path[0])
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "No route matches " + r.URL.Path})
+	}
+}
+
+func (s *Server) createCluster(w http.ResponseWriter, r *http.Request) {
+	body := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error()})
+		return
+	}
+
+	if name, _ := body["name"].(string); name == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"message": "Validation Failed",
+			"errors": []map[string]interface{}{
+				(&validationError{field: "name", code: "missing", message: "can't be blank"}).fields(),
+			},
+		})
+		return
+	}
+
+	uuid := newUUID()
+	c := &cluster{
+		ID:        graphQLID("Cluster", uuid),
+		UUID:      uuid,
+		CreatedAt: now(),
+	}
+	applyClusterAttributes(c, body)
+	s.clusters[c.UUID] = c
+
+	writeJSON(w, http.StatusCreated, s.clusterJSON(c))
+}
+
+func (s *Server) getCluster(w http.ResponseWriter, uuid string) {
+	c, ok := s.clusters[uuid]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.clusterJSON(c))
+}
+
+func (s *Server) updateCluster(w http.ResponseWriter, r *http.Request, uuid string) {
+	c, ok := s.clusters[uuid]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+		return
+	}
+
+	body := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error()})
+		return
+	}
+	applyClusterAttributes(c, body)
+
+	writeJSON(w, http.StatusOK, s.clusterJSON(c))
+}
+
+func (s *Server) deleteCluster(w http.ResponseWriter, uuid string) {
+	if _, ok := s.clusters[uuid]; !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+		return
+	}
+
+	delete(s.clusters, uuid)
+	for id, q := range s.clusterQueues {
+		if q.ClusterUUID == uuid {
+			delete(s.clusterQueues, id)
+		}
+	}
+	for id, t := range s.clusterAgentTokens {
+		if t.ClusterUUID == uuid {
+			delete(s.clusterAgentTokens, id)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func applyClusterAttributes(c *cluster, body map[string]interface{}) {
+	stringAttributes := map[string]*string{
+		"name":        &c.Name,
+		"description": &c.Description,
+		"emoji":       &c.Emoji,
+		"color":       &c.Color,
+	}
+	for key, attribute := range stringAttributes {
+		if value, ok := body[key].(string); ok {
+			*attribute = value
+		}
+	}
+}
+
+func (s *Server) clusterJSON(c *cluster) map[string]interface{} {
+	url := s.RestAPIURL() + "v2/organizations/" + s.OrgSlug + "/clusters/" + c.UUID
+
+	return map[string]interface{}{
+		"id":               c.UUID,
+		"graphql_id":       c.ID,
+		"default_queue_id": c.DefaultQueueID,
+		"name":             c.Name,
+		"description":      c.Description,
+		"emoji":            c.Emoji,
+		"color":            c.Color,
+		"url":              url,
+		"web_url":          "https://buildkite.com/organizations/" + s.OrgSlug + "/clusters/" + c.UUID,
+		"queues_url":       url + "/queues",
+		"created_at":       c.CreatedAt,
+	}
+}
+
+// serveClusterQueues serves /v2/organizations/{org}/clusters/{cluster}/queues, path is the remainder of the URL path
+func (s *Server) serveClusterQueues(w http.ResponseWriter, r *http.Request, c *cluster, path []string) {
+	if len(path) == 0 && r.Method == http.MethodPost {
+		s.createClusterQueue(w, r, c)
+		return
+	}
+	if len(path) == 0 {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "No route matches " + r.URL.Path})
+		return
+	}
+
+	q, ok := s.clusterQueues[path[0]]
+	if !ok || q.ClusterUUID != c.UUID {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+		return
+	}
+
+	switch {
+	case len(path) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.clusterQueueJSON(q))
+	case len(path) == 1 && r.Method == http.MethodPatch:
+		body := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error()})
+			return
+		}
+		applyClusterQueueAttributes(q, body)
+		writeJSON(w, http.StatusOK, s.clusterQueueJSON(q))
+	case len(path) == 1 && r.Method == http.MethodDelete:
+		delete(s.clusterQueues, q.UUID)
+		w.WriteHeader(http.StatusNoContent)
+	case len(path) == 2 && path[1] == "pause_dispatch" && r.Method == http.MethodPost:
+		q.DispatchPaused = true
+		writeJSON(w, http.StatusOK, s.clusterQueueJSON(q))
+	case len(path) == 2 && path[1] == "resume_dispatch" && r.Method == http.MethodPost:
+		q.DispatchPaused = false
+		writeJSON(w, http.StatusOK, s.clusterQueueJSON(q))
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "No route matches " + r.URL.Path})
+	}
+}
+
+func (s *Server) createClusterQueue(w http.ResponseWriter, r *http.Request, c *cluster) {
+	body := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error()})
+		return
+	}
+
+	key, _ := body["key"].(string)
+	var validationErr *validationError
+	if key == "" {
+		validationErr = &validationError{field: "key", code: "missing", message: "can't be blank"}
+	}
+	for _, q := range s.clusterQueues {
+		if q.ClusterUUID == c.UUID && q.Key == key {
+			validationErr = &validationError{field: "key", code: "already_exists", message: "has already been taken"}
+		}
+	}
+	if validationErr != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"message": "Validation Failed",
+			"errors":  []map[string]interface{}{validationErr.fields()},
+		})
+		return
+	}
+
+	uuid := newUUID()
+	q := &clusterQueue{
+		ID:          graphQLID("ClusterQueue", uuid),
+		UUID:        uuid,
+		ClusterUUID: c.UUID,
+		Key:         key,
+		CreatedAt:   now(),
+	}
+	applyClusterQueueAttributes(q, body)
+	s.clusterQueues[q.UUID] = q
+
+	writeJSON(w, http.StatusCreated, s.clusterQueueJSON(q))
+}
+
+func applyClusterQueueAttributes(q *clusterQueue, body map[string]interface{}) {
+	if description, ok := body["description"].(string); ok {
+		q.Description = description
+	}
+	if hostedAgents, ok := body["hosted_agents"].(map[string]interface{}); ok {
+		q.InstanceShape, _ = hostedAgents["instance_shape"].(string)
+		q.AgentImageRef, _ = mapOf(mapOf(hostedAgents["platform_settings"])["linux"])["agent_image_ref"].(string)
+	}
+}
+
+func (s *Server) clusterQueueJSON(q *clusterQueue) map[string]interface{} {
+	url := s.RestAPIURL() + "v2/organizations/" + s.OrgSlug + "/clusters/" + q.ClusterUUID + "/queues/" + q.UUID
+
+	var hostedAgents interface{}
+	if q.InstanceShape != "" {
+		// responses describe the instance shape instead of only naming it
+		hostedAgents = map[string]interface{}{
+			"instance_shape": map[string]interface{}{"name": q.InstanceShape},
+			"platform_settings": map[string]interface{}{
+				"linux": map[string]interface{}{"agent_image_ref": q.AgentImageRef},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"id":              q.UUID,
+		"graphql_id":      q.ID,
+		"cluster_url":     s.RestAPIURL() + "v2/organizations/" + s.OrgSlug + "/clusters/" + q.ClusterUUID,
+		"key":             q.Key,
+		"description":     q.Description,
+		"dispatch_paused": q.DispatchPaused,
+		"hosted":          hostedAgents != nil,
+		"hosted_agents":   hostedAgents,
+		"url":             url,
+		"web_url":         "https://buildkite.com/organizations/" + s.OrgSlug + "/clusters/" + q.ClusterUUID + "/queues/" + q.UUID,
+		"created_at":      q.CreatedAt,
+	}
+}
+
+// serveClusterAgentTokens serves /v2/organizations/{org}/clusters/{cluster}/tokens, path is the remainder of the
+// URL path
+func (s *Server) serveClusterAgentTokens(w http.ResponseWriter, r *http.Request, c *cluster, path []string) {
+	if len(path) == 0 && r.Method == http.MethodPost {
+		body := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error()})
+			return
+		}
+
+		uuid := newUUID()
+		t := &clusterAgentToken{
+			ID:          graphQLID("ClusterToken", uuid),
+			UUID:        uuid,
+			ClusterUUID: c.UUID,
+			Token:       newUUID(),
+			CreatedAt:   now(),
+		}
+		applyClusterAgentTokenAttributes(t, body)
+		s.clusterAgentTokens[t.UUID] = t
+
+		response := s.clusterAgentTokenJSON(t)
+		// the token is only revealed when it is created
+		response["token"] = t.Token
+		writeJSON(w, http.StatusCreated, response)
+		return
+	}
+	if len(path) != 1 {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "No route matches " + r.URL.Path})
+		return
+	}
+
+	t, ok := s.clusterAgentTokens[path[0]]
+	if !ok || t.ClusterUUID != c.UUID {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.clusterAgentTokenJSON(t))
+	case http.MethodPatch:
+		body := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error()})
+			return
+		}
+		applyClusterAgentTokenAttributes(t, body)
+		writeJSON(w, http.StatusOK, s.clusterAgentTokenJSON(t))
+	case http.MethodDelete:
+		delete(s.clusterAgentTokens, t.UUID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "No route matches " + r.URL.Path})
+	}
+}
+
+func applyClusterAgentTokenAttributes(t *clusterAgentToken, body map[string]interface{}) {
+	if description, ok := body["description"].(string); ok {
+		t.Description = description
+	}
+	if allowedIPAddresses, ok := body["allowed_ip_addresses"].(string); ok {
+		t.AllowedIPAddresses = allowedIPAddresses
+	}
+}
+
+func (s *Server) clusterAgentTokenJSON(t *clusterAgentToken) map[string]interface{} {
+	clusterURL := s.RestAPIURL() + "v2/organizations/" + s.OrgSlug + "/clusters/" + t.ClusterUUID
+
+	return map[string]interface{}{
+		"id":                   t.UUID,
+		"graphql_id":           t.ID,
+		"description":          t.Description,
+		"allowed_ip_addresses": t.AllowedIPAddresses,
+		"url":                  clusterURL + "/tokens/" + t.UUID,
+		"cluster_url":          clusterURL,
+		"created_at":           t.CreatedAt,
+	}
+}
@@ -0,0 +1,907 @@
+package testserver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// the fake does not parse GraphQL, it only looks at the first field of the operation and its arguments
+	rootFieldPattern = regexp.MustCompile(`(?s)^\s*(?:query|mutation)[^{]*\{\s*(?:(\w+)\s*:\s*)?(\w+)\s*(?:\(([^)]*)\))?`)
+	argumentPattern  = regexp.MustCompile(`(\w+)\s*:\s*\$(\w+)`)
+)
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// resolver returns the value of a root field, nil if the requested object does not exist
+type resolver func(args map[string]interface{}) (interface{}, error)
+
+type team struct {
+	ID                string
+	UUID              string
+	Slug              string
+	Name              string
+	Description       string
+	Privacy           string
+	IsDefaultTeam     bool
+	DefaultMemberRole string
+	CreatedAt         string
+}
+
+type teamMember struct {
+	ID        string
+	UUID      string
+	Role      string
+	CreatedAt string
+	TeamID    string
+	UserID    string
+}
+
+type teamPipeline struct {
+	ID          string
+	UUID        string
+	AccessLevel string
+	CreatedAt   string
+	TeamID      string
+	PipelineID  string
+}
+
+type pipelineSchedule struct {
+	ID         string
+	UUID       string
+	Label      string
+	Cronline   string
+	Message    string
+	Commit     string
+	Branch     string
+	Env        []string
+	Enabled    bool
+	CreatedAt  string
+	PipelineID string
+}
+
+type orgMember struct {
+	ID        string
+	UUID      string
+	Role      string
+	CreatedAt string
+	UserID    string
+	UserName  string
+	UserEmail string
+}
+
+func (s *Server) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	req := graphQLRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, graphQLErrors(err.Error()))
+		return
+	}
+
+	match := rootFieldPattern.FindStringSubmatch(req.Query)
+	if match == nil {
+		writeJSON(w, http.StatusOK, graphQLErrors("Unable to parse query"))
+		return
+	}
+	alias, field := match[1], match[2]
+	if alias == "" {
+		alias = field
+	}
+
+	args := map[string]interface{}{}
+	for _, argument := range argumentPattern.FindAllStringSubmatch(match[3], -1) {
+		args[argument[1]] = req.Variables[argument[2]]
+	}
+
+	resolve, ok := s.resolvers()[field]
+	if !ok {
+		writeJSON(w, http.StatusOK, graphQLErrors(fmt.Sprintf("Field '%s' doesn't exist on type 'Query'", field)))
+		return
+	}
+
+	s.mutex.Lock()
+	if !s.hasScope("graphql") {
+		s.mutex.Unlock()
+		writeJSON(w, http.StatusForbidden, map[string]interface{}{
+			"message": "The API Access Token is missing the graphql scope",
+		})
+		return
+	}
+	s.first, s.after, s.search = 0, "", ""
+	if first, ok := req.Variables["first"].(float64); ok {
+		s.first = int(first)
+	}
+	if after, ok := req.Variables["after"].(string); ok {
+		s.after = after
+	}
+	if search, ok := req.Variables["search"].(string); ok {
+		s.search = search
+	}
+	result, err := resolve(args)
+	s.mutex.Unlock()
+
+	if validationErr, ok := err.(*validationError); ok {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{alias: nil},
+			"errors": []map[string]interface{}{
+				{"message": validationErr.Error(), "extensions": map[string]interface{}{"field": validationErr.field}},
+			},
+		})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusOK, graphQLErrors(err.Error()))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{alias: result},
+	})
+}
+
+func graphQLErrors(message string) map[string]interface{} {
+	return map[string]interface{}{
+		"data": nil,
+		"errors": []map[string]interface{}{
+			{"message": message},
+		},
+	}
+}
+
+// connection returns the page of nodes selected by the $first and $after variables of the request
+func (s *Server) connection(nodes []map[string]interface{}) map[string]interface{} {
+	// nodes are kept in maps, order them to get stable cursors
+	sort.Slice(nodes, func(i, j int) bool {
+		return fmt.Sprint(nodes[i]["id"]) < fmt.Sprint(nodes[j]["id"])
+	})
+
+	start := 0
+	if s.after != "" {
+		decoded, _ := base64.StdEncoding.DecodeString(s.after)
+		if index, err := strconv.Atoi(strings.TrimPrefix(string(decoded), "cursor:")); err == nil {
+			start = index + 1
+		}
+	}
+	if start > len(nodes) {
+		start = len(nodes)
+	}
+
+	limit := s.first
+	if limit <= 0 || limit > s.PageSize {
+		limit = s.PageSize
+	}
+	end := start + limit
+	if end > len(nodes) {
+		end = len(nodes)
+	}
+
+	edges := []interface{}{}
+	endCursor := ""
+	for i := start; i < end; i++ {
+		endCursor = base64.StdEncoding.EncodeToString([]byte("cursor:" + strconv.Itoa(i)))
+		edges = append(edges, map[string]interface{}{
+			"cursor": endCursor,
+			"node":   nodes[i],
+		})
+	}
+
+	return map[string]interface{}{
+		"count": len(nodes),
+		"edges": edges,
+		"pageInfo": map[string]interface{}{
+			"hasNextPage": end < len(nodes),
+			"endCursor":   endCursor,
+		},
+	}
+}
+
+func (s *Server) resolvers() map[string]resolver {
+	return map[string]resolver{
+		"organization":                 s.resolveOrganization,
+		"node":                         s.resolveNode,
+		"pipeline":                     s.resolvePipeline,
+		"pipelineCreate":               s.resolvePipelineCreate,
+		"pipelineUpdate":               s.resolvePipelineUpdate,
+		"team":                         s.resolveTeam,
+		"teamCreate":                   s.resolveTeamCreate,
+		"teamUpdate":                   s.resolveTeamUpdate,
+		"teamDelete":                   s.resolveTeamDelete,
+		"teamMemberCreate":             s.resolveTeamMemberCreate,
+		"teamMemberUpdate":             s.resolveTeamMemberUpdate,
+		"teamMemberDelete":             s.resolveTeamMemberDelete,
+		"teamPipelineCreate":           s.resolveTeamPipelineCreate,
+		"teamPipelineUpdate":           s.resolveTeamPipelineUpdate,
+		"teamPipelineDelete":           s.resolveTeamPipelineDelete,
+		"pipelineSchedule":             s.resolvePipelineSchedule,
+		"pipelineScheduleCreate":       s.resolvePipelineScheduleCreate,
+		"pipelineScheduleUpdate":       s.resolvePipelineScheduleUpdate,
+		"pipelineScheduleDelete":       s.resolvePipelineScheduleDelete,
+		"organizationMember":           s.resolveOrganizationMember,
+		"organizationMemberUpdate":     s.resolveOrganizationMemberUpdate,
+		"organizationMemberDelete":     s.resolveOrganizationMemberDelete,
+		"pipelineTemplateCreate":       s.resolvePipelineTemplateCreate,
+		"pipelineTemplateUpdate":       s.resolvePipelineTemplateUpdate,
+		"pipelineTemplateDelete":       s.resolvePipelineTemplateDelete,
+		"organizationInvitationCreate": s.resolveOrganizationInvitationCreate,
+		"organizationInvitationRevoke": s.resolveOrganizationInvitationRevoke,
+		"agentTokenCreate":             s.resolveAgentTokenCreate,
+		"agentTokenRevoke":             s.resolveAgentTokenRevoke,
+		"suiteCreate":                  s.resolveSuiteCreate,
+		"suiteUpdate":                  s.resolveSuiteUpdate,
+		"suiteDelete":                  s.resolveSuiteDelete,
+		"teamSuiteCreate":              s.resolveTeamSuiteCreate,
+		"teamSuiteUpdate":              s.resolveTeamSuiteUpdate,
+		"teamSuiteDelete":              s.resolveTeamSuiteDelete,
+	}
+}
+
+func (s *Server) resolveOrganization(args map[string]interface{}) (interface{}, error) {
+	if args["slug"] != s.OrgSlug {
+		return nil, nil
+	}
+	// like Buildkite, the search matches parts of names and email addresses
+	search := strings.ToLower(s.search)
+	members := []map[string]interface{}{}
+	for _, member := range s.orgMembers {
+		if strings.Contains(strings.ToLower(member.UserName), search) || strings.Contains(strings.ToLower(member.UserEmail), search) {
+			members = append(members, orgMemberNode(member))
+		}
+	}
+
+	return map[string]interface{}{
+		"id":      s.orgID,
+		"slug":    s.OrgSlug,
+		"members": s.connection(members),
+	}, nil
+}
+
+func (s *Server) resolveNode(args map[string]interface{}) (interface{}, error) {
+	id, _ := args["id"].(string)
+
+	switch nodeType(id) {
+	case "TeamMember":
+		if tm, ok := s.teamMembers[id]; ok {
+			return teamMemberNode(tm), nil
+		}
+	case "TeamPipeline":
+		if tp, ok := s.teamPipelines[id]; ok {
+			return s.teamPipelineNode(tp), nil
+		}
+	case "AgentToken":
+		if t, ok := s.agentTokens[id]; ok {
+			return agentTokenNode(t), nil
+		}
+	case "OrganizationInvitation":
+		if invitation, ok := s.invitations[id]; ok {
+			return s.organizationInvitationNode(invitation), nil
+		}
+	case "PipelineTemplate":
+		if t, ok := s.pipelineTemplates[id]; ok {
+			return pipelineTemplateNode(t), nil
+		}
+	case "Suite":
+		if suite, ok := s.suites[id]; ok {
+			return s.suiteNode(suite), nil
+		}
+	case "TeamSuite":
+		if ts, ok := s.teamSuites[id]; ok {
+			return teamSuiteNode(ts), nil
+		}
+	}
+	return nil, nil
+}
+
+// orgScoped strips the organization from slugs of the form org/slug
+func (s *Server) orgScoped(slug interface{}) (string, bool) {
+	value, _ := slug.(string)
+	if !strings.HasPrefix(value, s.OrgSlug+"/") {
+		return "", false
+	}
+	return strings.TrimPrefix(value, s.OrgSlug+"/"), true
+}
+
+func (s *Server) pipelineByID(id string) *pipeline {
+	for _, p := range s.pipelines {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Server) resolvePipeline(args map[string]interface{}) (interface{}, error) {
+	slug, ok := s.orgScoped(args["slug"])
+	if !ok {
+		return nil, nil
+	}
+	p, ok := s.pipelines[slug]
+	if !ok {
+		return nil, nil
+	}
+	return s.pipelineNode(p), nil
+}
+
+func (s *Server) pipelineNode(p *pipeline) map[string]interface{} {
+	teams := []map[string]interface{}{}
+	for _, tp := range s.teamPipelines {
+		if tp.PipelineID == p.ID {
+			teams = append(teams, s.teamPipelineNode(tp))
+		}
+	}
+
+	return map[string]interface{}{
+		"id":   p.ID,
+		"uuid": p.UUID,
+		"slug": p.Slug,
+		"name": p.Name,
+		"steps": map[string]interface{}{
+			"yaml": s.pipelineConfiguration(p),
+		},
+		"pipelineTemplate": s.pipelineTemplateRef(p),
+		"teams":            s.connection(teams),
+	}
+}
+
+func (s *Server) resolvePipelineCreate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	if input["organizationId"] != s.orgID {
+		return nil, fmt.Errorf("No organization found with id %v", input["organizationId"])
+	}
+
+	name, _ := input["name"].(string)
+	repository, _ := mapOf(input["repository"])["url"].(string)
+	p, err := s.newPipeline(name, repository)
+	if err != nil {
+		return nil, err
+	}
+	if yaml, ok := mapOf(input["steps"])["yaml"].(string); ok {
+		p.Configuration = yaml
+	}
+	if err := s.applyPipelineTemplate(p, input); err != nil {
+		return nil, err
+	}
+
+	teams, _ := input["teams"].([]interface{})
+	for _, t := range teams {
+		teamInput := mapOf(t)
+		accessLevel, _ := teamInput["accessLevel"].(string)
+		s.addPipelineTeams(p, []interface{}{teamInput["id"]}, accessLevel)
+	}
+
+	return map[string]interface{}{
+		"pipeline": s.pipelineNode(p),
+	}, nil
+}
+
+func (s *Server) resolvePipelineUpdate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	id, _ := input["id"].(string)
+	p := s.pipelineByID(id)
+	if p == nil {
+		return nil, fmt.Errorf("No pipeline found with id %s", id)
+	}
+
+	if err := s.applyPipelineTemplate(p, input); err != nil {
+		return nil, err
+	}
+	if yaml, ok := mapOf(input["steps"])["yaml"].(string); ok {
+		if p.TemplateID != "" {
+			return nil, fmt.Errorf("The steps of a pipeline using a template can't be changed")
+		}
+		p.Configuration = yaml
+	}
+
+	return map[string]interface{}{
+		"pipeline": s.pipelineNode(p),
+	}, nil
+}
+
+func (s *Server) resolveTeam(args map[string]interface{}) (interface{}, error) {
+	slug, ok := s.orgScoped(args["slug"])
+	if !ok {
+		return nil, nil
+	}
+	t, ok := s.teams[slug]
+	if !ok {
+		return nil, nil
+	}
+	return teamNode(t), nil
+}
+
+func teamNode(t *team) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                t.ID,
+		"uuid":              t.UUID,
+		"slug":              t.Slug,
+		"name":              t.Name,
+		"description":       t.Description,
+		"privacy":           t.Privacy,
+		"isDefaultTeam":     t.IsDefaultTeam,
+		"defaultMemberRole": t.DefaultMemberRole,
+		"createdAt":         t.CreatedAt,
+	}
+}
+
+func (s *Server) teamByID(id string) *team {
+	for _, t := range s.teams {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *Server) resolveTeamCreate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	if input["organizationID"] != s.orgID {
+		return nil, fmt.Errorf("No organization found with id %v", input["organizationID"])
+	}
+
+	name, _ := input["name"].(string)
+	slug := slugify(name)
+	if _, exists := s.teams[slug]; exists {
+		return nil, fmt.Errorf("Name has already been taken")
+	}
+
+	uuid := newUUID()
+	t := &team{
+		ID:        graphQLID("Team", uuid),
+		UUID:      uuid,
+		Slug:      slug,
+		CreatedAt: now(),
+	}
+	applyTeamInput(t, input)
+	s.teams[slug] = t
+
+	return map[string]interface{}{
+		"teamEdge": map[string]interface{}{
+			"node": teamNode(t),
+		},
+	}, nil
+}
+
+func (s *Server) resolveTeamUpdate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	id, _ := input["id"].(string)
+	t := s.teamByID(id)
+	if t == nil {
+		return nil, fmt.Errorf("No team found with id %s", id)
+	}
+
+	applyTeamInput(t, input)
+
+	return map[string]interface{}{
+		"team": teamNode(t),
+	}, nil
+}
+
+func applyTeamInput(t *team, input map[string]interface{}) {
+	stringAttributes := map[string]*string{
+		"name":              &t.Name,
+		"description":       &t.Description,
+		"privacy":           &t.Privacy,
+		"defaultMemberRole": &t.DefaultMemberRole,
+	}
+	for key, attribute := range stringAttributes {
+		if value, ok := input[key].(string); ok {
+			*attribute = value
+		}
+	}
+	if value, ok := input["isDefaultTeam"].(bool); ok {
+		t.IsDefaultTeam = value
+	}
+}
+
+func (s *Server) resolveTeamDelete(args map[string]interface{}) (interface{}, error) {
+	id, _ := inputOf(args)["id"].(string)
+	t := s.teamByID(id)
+	if t == nil {
+		return nil, fmt.Errorf("No team found with id %s", id)
+	}
+
+	delete(s.teams, t.Slug)
+	for memberID, tm := range s.teamMembers {
+		if tm.TeamID == id {
+			delete(s.teamMembers, memberID)
+		}
+	}
+	for teamPipelineID, tp := range s.teamPipelines {
+		if tp.TeamID == id {
+			delete(s.teamPipelines, teamPipelineID)
+		}
+	}
+	for teamSuiteID, ts := range s.teamSuites {
+		if ts.TeamID == id {
+			delete(s.teamSuites, teamSuiteID)
+		}
+	}
+
+	return map[string]interface{}{
+		"deletedTeamID": id,
+	}, nil
+}
+
+func teamMemberNode(tm *teamMember) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        tm.ID,
+		"uuid":      tm.UUID,
+		"role":      tm.Role,
+		"createdAt": tm.CreatedAt,
+		"team":      map[string]interface{}{"id": tm.TeamID},
+		"user":      map[string]interface{}{"id": tm.UserID},
+	}
+}
+
+func (s *Server) resolveTeamMemberCreate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	teamID, _ := input["teamID"].(string)
+	userID, _ := input["userID"].(string)
+
+	if s.teamByID(teamID) == nil {
+		return nil, fmt.Errorf("No team found with id %s", teamID)
+	}
+	if s.memberByUserID(userID) == nil {
+		return nil, fmt.Errorf("No user found with id %s", userID)
+	}
+	for _, tm := range s.teamMembers {
+		if tm.TeamID == teamID && tm.UserID == userID {
+			return nil, fmt.Errorf("User is already a member of this team")
+		}
+	}
+
+	uuid := newUUID()
+	tm := &teamMember{
+		ID:        graphQLID("TeamMember", uuid),
+		UUID:      uuid,
+		Role:      "MEMBER",
+		CreatedAt: now(),
+		TeamID:    teamID,
+		UserID:    userID,
+	}
+	s.teamMembers[tm.ID] = tm
+
+	return map[string]interface{}{
+		"teamMemberEdge": map[string]interface{}{
+			"node": teamMemberNode(tm),
+		},
+	}, nil
+}
+
+func (s *Server) resolveTeamMemberUpdate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	id, _ := input["id"].(string)
+	tm, ok := s.teamMembers[id]
+	if !ok {
+		return nil, fmt.Errorf("No team member found with id %s", id)
+	}
+
+	if role, ok := input["role"].(string); ok {
+		tm.Role = role
+	}
+
+	return map[string]interface{}{
+		"teamMember": teamMemberNode(tm),
+	}, nil
+}
+
+func (s *Server) resolveTeamMemberDelete(args map[string]interface{}) (interface{}, error) {
+	id, _ := inputOf(args)["id"].(string)
+	if _, ok := s.teamMembers[id]; !ok {
+		return nil, fmt.Errorf("No team member found with id %s", id)
+	}
+
+	delete(s.teamMembers, id)
+
+	return map[string]interface{}{
+		"deletedTeamMemberID": id,
+	}, nil
+}
+
+func (s *Server) teamPipelineNode(tp *teamPipeline) map[string]interface{} {
+	pipelineSlug := ""
+	if p := s.pipelineByID(tp.PipelineID); p != nil {
+		pipelineSlug = p.Slug
+	}
+
+	return map[string]interface{}{
+		"id":          tp.ID,
+		"uuid":        tp.UUID,
+		"accessLevel": tp.AccessLevel,
+		"createdAt":   tp.CreatedAt,
+		"team":        map[string]interface{}{"id": tp.TeamID},
+		"pipeline":    map[string]interface{}{"id": tp.PipelineID, "slug": pipelineSlug},
+	}
+}
+
+func (s *Server) newTeamPipeline(t *team, p *pipeline, accessLevel string) *teamPipeline {
+	uuid := newUUID()
+	tp := &teamPipeline{
+		ID:          graphQLID("TeamPipeline", uuid),
+		UUID:        uuid,
+		AccessLevel: accessLevel,
+		CreatedAt:   now(),
+		TeamID:      t.ID,
+		PipelineID:  p.ID,
+	}
+	s.teamPipelines[tp.ID] = tp
+	return tp
+}
+
+func (s *Server) resolveTeamPipelineCreate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	teamID, _ := input["teamID"].(string)
+	pipelineID, _ := input["pipelineID"].(string)
+
+	t := s.teamByID(teamID)
+	if t == nil {
+		return nil, fmt.Errorf("No team found with id %s", teamID)
+	}
+	p := s.pipelineByID(pipelineID)
+	if p == nil {
+		return nil, fmt.Errorf("No pipeline found with id %s", pipelineID)
+	}
+	for _, tp := range s.teamPipelines {
+		if tp.TeamID == teamID && tp.PipelineID == pipelineID {
+			return nil, fmt.Errorf("Pipeline has already been added to this team")
+		}
+	}
+
+	accessLevel, ok := input["accessLevel"].(string)
+	if !ok {
+		accessLevel = "READ_ONLY"
+	}
+	tp := s.newTeamPipeline(t, p, accessLevel)
+
+	return map[string]interface{}{
+		"teamPipelineEdge": map[string]interface{}{
+			"node": s.teamPipelineNode(tp),
+		},
+	}, nil
+}
+
+func (s *Server) resolveTeamPipelineUpdate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	id, _ := input["id"].(string)
+	tp, ok := s.teamPipelines[id]
+	if !ok {
+		return nil, fmt.Errorf("No team pipeline found with id %s", id)
+	}
+
+	if accessLevel, ok := input["accessLevel"].(string); ok {
+		tp.AccessLevel = accessLevel
+	}
+
+	return map[string]interface{}{
+		"teamPipeline": s.teamPipelineNode(tp),
+	}, nil
+}
+
+func (s *Server) resolveTeamPipelineDelete(args map[string]interface{}) (interface{}, error) {
+	id, _ := inputOf(args)["id"].(string)
+	if _, ok := s.teamPipelines[id]; !ok {
+		return nil, fmt.Errorf("No team pipeline found with id %s", id)
+	}
+
+	delete(s.teamPipelines, id)
+
+	return map[string]interface{}{
+		"deletedTeamPipelineID": id,
+	}, nil
+}
+
+func (s *Server) pipelineScheduleNode(schedule *pipelineSchedule) map[string]interface{} {
+	pipelineSlug := ""
+	if p := s.pipelineByID(schedule.PipelineID); p != nil {
+		pipelineSlug = p.Slug
+	}
+
+	return map[string]interface{}{
+		"id":        schedule.ID,
+		"uuid":      schedule.UUID,
+		"label":     schedule.Label,
+		"cronline":  schedule.Cronline,
+		"message":   schedule.Message,
+		"commit":    schedule.Commit,
+		"branch":    schedule.Branch,
+		"env":       schedule.Env,
+		"enabled":   schedule.Enabled,
+		"createdAt": schedule.CreatedAt,
+		"pipeline":  map[string]interface{}{"id": schedule.PipelineID, "slug": pipelineSlug},
+	}
+}
+
+func (s *Server) resolvePipelineSchedule(args map[string]interface{}) (interface{}, error) {
+	// pipeline schedules are addressed as org/pipeline/uuid
+	slug, ok := s.orgScoped(args["slug"])
+	if !ok {
+		return nil, nil
+	}
+	parts := strings.SplitN(slug, "/", 2)
+	if len(parts) != 2 {
+		return nil, nil
+	}
+
+	for _, schedule := range s.schedules {
+		p := s.pipelineByID(schedule.PipelineID)
+		if schedule.UUID == parts[1] && p != nil && p.Slug == parts[0] {
+			return s.pipelineScheduleNode(schedule), nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) resolvePipelineScheduleCreate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	pipelineID, _ := input["pipelineID"].(string)
+	if s.pipelineByID(pipelineID) == nil {
+		return nil, fmt.Errorf("No pipeline found with id %s", pipelineID)
+	}
+
+	uuid := newUUID()
+	schedule := &pipelineSchedule{
+		ID:         graphQLID("PipelineSchedule", uuid),
+		UUID:       uuid,
+		CreatedAt:  now(),
+		PipelineID: pipelineID,
+	}
+	applyPipelineScheduleInput(schedule, input)
+	s.schedules[schedule.ID] = schedule
+
+	return map[string]interface{}{
+		"pipelineScheduleEdge": map[string]interface{}{
+			"node": s.pipelineScheduleNode(schedule),
+		},
+	}, nil
+}
+
+func (s *Server) resolvePipelineScheduleUpdate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	id, _ := input["id"].(string)
+	schedule, ok := s.schedules[id]
+	if !ok {
+		return nil, fmt.Errorf("No pipeline schedule found with id %s", id)
+	}
+
+	applyPipelineScheduleInput(schedule, input)
+
+	return map[string]interface{}{
+		"pipelineSchedule": s.pipelineScheduleNode(schedule),
+	}, nil
+}
+
+func applyPipelineScheduleInput(schedule *pipelineSchedule, input map[string]interface{}) {
+	stringAttributes := map[string]*string{
+		"label":    &schedule.Label,
+		"cronline": &schedule.Cronline,
+		"message":  &schedule.Message,
+		"commit":   &schedule.Commit,
+		"branch":   &schedule.Branch,
+	}
+	for key, attribute := range stringAttributes {
+		if value, ok := input[key].(string); ok {
+			*attribute = value
+		}
+	}
+	if env, ok := input["env"].(string); ok {
+		schedule.Env = []string{}
+		for _, line := range strings.Split(env, "\n") {
+			if line != "" {
+				schedule.Env = append(schedule.Env, line)
+			}
+		}
+	}
+	if enabled, ok := input["enabled"].(bool); ok {
+		schedule.Enabled = enabled
+	}
+}
+
+func (s *Server) resolvePipelineScheduleDelete(args map[string]interface{}) (interface{}, error) {
+	id, _ := inputOf(args)["id"].(string)
+	if _, ok := s.schedules[id]; !ok {
+		return nil, fmt.Errorf("No pipeline schedule found with id %s", id)
+	}
+
+	delete(s.schedules, id)
+
+	return map[string]interface{}{
+		"deletedPipelineScheduleID": id,
+	}, nil
+}
+
+func orgMemberNode(member *orgMember) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        member.ID,
+		"uuid":      member.UUID,
+		"role":      member.Role,
+		"createdAt": member.CreatedAt,
+		"user": map[string]interface{}{
+			"id":    member.UserID,
+			"name":  member.UserName,
+			"email": member.UserEmail,
+		},
+	}
+}
+
+func (s *Server) memberByUserID(userID string) *orgMember {
+	for _, member := range s.orgMembers {
+		if member.UserID == userID {
+			return member
+		}
+	}
+	return nil
+}
+
+func (s *Server) memberByID(id string) *orgMember {
+	for _, member := range s.orgMembers {
+		if member.ID == id {
+			return member
+		}
+	}
+	return nil
+}
+
+func (s *Server) resolveOrganizationMember(args map[string]interface{}) (interface{}, error) {
+	uuid, ok := s.orgScoped(args["slug"])
+	if !ok {
+		return nil, nil
+	}
+	member, ok := s.orgMembers[uuid]
+	if !ok {
+		return nil, nil
+	}
+	return orgMemberNode(member), nil
+}
+
+func (s *Server) resolveOrganizationMemberUpdate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	id, _ := input["id"].(string)
+	member := s.memberByID(id)
+	if member == nil {
+		return nil, fmt.Errorf("No organization member found with id %s", id)
+	}
+
+	if role, ok := input["role"].(string); ok {
+		member.Role = role
+	}
+
+	return map[string]interface{}{
+		"organizationMember": orgMemberNode(member),
+	}, nil
+}
+
+func (s *Server) resolveOrganizationMemberDelete(args map[string]interface{}) (interface{}, error) {
+	id, _ := inputOf(args)["id"].(string)
+	member := s.memberByID(id)
+	if member == nil {
+		return nil, fmt.Errorf("No organization member found with id %s", id)
+	}
+
+	delete(s.orgMembers, member.UUID)
+	for teamMemberID, tm := range s.teamMembers {
+		if tm.UserID == member.UserID {
+			delete(s.teamMembers, teamMemberID)
+		}
+	}
+
+	return map[string]interface{}{
+		"deletedOrganizationMemberID": id,
+	}, nil
+}
+
+func inputOf(args map[string]interface{}) map[string]interface{} {
+	return mapOf(args["input"])
+}
+
+func mapOf(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
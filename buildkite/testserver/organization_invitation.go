@@ -0,0 +1,160 @@
+package testserver
+
+import (
+	"fmt"
+)
+
+type invitationTeam struct {
+	TeamID string
+	Role   string
+}
+
+type organizationInvitation struct {
+	ID         string
+	UUID       string
+	Email      string
+	Role       string
+	State      string
+	CreatedAt  string
+	AcceptedAt string
+	AcceptedBy string
+	Teams      []invitationTeam
+}
+
+func (s *Server) organizationInvitationNode(invitation *organizationInvitation) map[string]interface{} {
+	teams := []map[string]interface{}{}
+	for i, team := range invitation.Teams {
+		teams = append(teams, map[string]interface{}{
+			"id":   graphQLID("OrganizationInvitationTeamAssignment", fmt.Sprintf("%s-%d", invitation.UUID, i)),
+			"role": team.Role,
+			"team": map[string]interface{}{"id": team.TeamID},
+		})
+	}
+
+	var acceptedAt, acceptedBy interface{}
+	if invitation.AcceptedAt != "" {
+		acceptedAt = invitation.AcceptedAt
+	}
+	if member := s.memberByUserID(invitation.AcceptedBy); member != nil {
+		acceptedBy = orgMemberNode(member)["user"]
+	}
+
+	return map[string]interface{}{
+		"id":         invitation.ID,
+		"uuid":       invitation.UUID,
+		"email":      invitation.Email,
+		"role":       invitation.Role,
+		"state":      invitation.State,
+		"createdAt":  invitation.CreatedAt,
+		"acceptedAt": acceptedAt,
+		"acceptedBy": acceptedBy,
+		"teams":      s.connection(teams),
+	}
+}
+
+func (s *Server) resolveOrganizationInvitationCreate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	if input["organizationID"] != s.orgID {
+		return nil, fmt.Errorf("No organization found with id %v", input["organizationID"])
+	}
+
+	role, _ := input["role"].(string)
+	if role == "" {
+		role = "MEMBER"
+	}
+
+	var teams []invitationTeam
+	teamInputs, _ := input["teams"].([]interface{})
+	for _, teamInput := range teamInputs {
+		teamID, _ := mapOf(teamInput)["id"].(string)
+		if s.teamByID(teamID) == nil {
+			return nil, fmt.Errorf("No team found with id %s", teamID)
+		}
+		teamRole, _ := mapOf(teamInput)["role"].(string)
+		if teamRole == "" {
+			teamRole = "MEMBER"
+		}
+		teams = append(teams, invitationTeam{TeamID: teamID, Role: teamRole})
+	}
+
+	edges := []interface{}{}
+	emails, _ := input["emails"].([]interface{})
+	for _, emailInput := range emails {
+		email, _ := emailInput.(string)
+		for _, member := range s.orgMembers {
+			if member.UserEmail == email {
+				return nil, &validationError{field: "emails", code: "invalid", message: email + " is already a member"}
+			}
+		}
+
+		uuid := newUUID()
+		invitation := &organizationInvitation{
+			ID:        graphQLID("OrganizationInvitation", uuid),
+			UUID:      uuid,
+			Email:     email,
+			Role:      role,
+			State:     "PENDING",
+			CreatedAt: now(),
+			Teams:     teams,
+		}
+		s.invitations[invitation.ID] = invitation
+		edges = append(edges, map[string]interface{}{"node": s.organizationInvitationNode(invitation)})
+	}
+
+	return map[string]interface{}{
+		"invitationEdges": edges,
+	}, nil
+}
+
+func (s *Server) resolveOrganizationInvitationRevoke(args map[string]interface{}) (interface{}, error) {
+	id, _ := inputOf(args)["id"].(string)
+	invitation, ok := s.invitations[id]
+	if !ok {
+		return nil, fmt.Errorf("No organization invitation found with id %s", id)
+	}
+	if invitation.State != "PENDING" {
+		return nil, fmt.Errorf("Invitation has already been %s", invitation.State)
+	}
+
+	invitation.State = "REVOKED"
+
+	return map[string]interface{}{
+		"organizationInvitation": s.organizationInvitationNode(invitation),
+	}, nil
+}
+
+// AcceptInvitation accepts the pending invitation sent to email the way the invited person would, it makes them a
+// member of the organization and the teams of the invitation. It returns the UUID of the membership and the GraphQL
+// id of the user.
+func (s *Server) AcceptInvitation(email string, name string) (string, string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, invitation := range s.invitations {
+		if invitation.Email != email || invitation.State != "PENDING" {
+			continue
+		}
+
+		member := s.addOrganizationMember(name, email, invitation.Role)
+		for _, team := range invitation.Teams {
+			uuid := newUUID()
+			tm := &teamMember{
+				ID:        graphQLID("TeamMember", uuid),
+				UUID:      uuid,
+				Role:      team.Role,
+				CreatedAt: now(),
+				TeamID:    team.TeamID,
+				UserID:    member.UserID,
+			}
+			s.teamMembers[tm.ID] = tm
+		}
+
+		invitation.State = "ACCEPTED"
+		invitation.AcceptedAt = now()
+		invitation.AcceptedBy = member.UserID
+
+		return member.UUID, member.UserID, nil
+	}
+
+	return "", "", fmt.Errorf("no pending invitation for %s", email)
+}
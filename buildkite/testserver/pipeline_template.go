@@ -0,0 +1,162 @@
+package testserver
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+type pipelineTemplate struct {
+	ID            string
+	UUID          string
+	Name          string
+	Description   string
+	Configuration string
+	Available     bool
+}
+
+func pipelineTemplateNode(t *pipelineTemplate) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            t.ID,
+		"uuid":          t.UUID,
+		"name":          t.Name,
+		"description":   t.Description,
+		"configuration": t.Configuration,
+		"available":     t.Available,
+	}
+}
+
+// pipelineConfiguration returns the YAML steps of the pipeline, which come from its template if it uses one
+func (s *Server) pipelineConfiguration(p *pipeline) string {
+	if t, ok := s.pipelineTemplates[p.TemplateID]; ok {
+		return normalizeYAML(t.Configuration)
+	}
+	return normalizeYAML(p.Configuration)
+}
+
+// normalizeYAML formats the steps like Buildkite does when it saves them, keys are sorted, indentation and quoting are
+// changed and comments are dropped
+func normalizeYAML(configuration string) string {
+	var steps interface{}
+	if err := yaml.Unmarshal([]byte(configuration), &steps); err != nil || steps == nil {
+		return configuration
+	}
+
+	var normalized bytes.Buffer
+	encoder := yaml.NewEncoder(&normalized)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(steps); err != nil {
+		return configuration
+	}
+	return normalized.String()
+}
+
+func (s *Server) pipelineTemplateRef(p *pipeline) interface{} {
+	t, ok := s.pipelineTemplates[p.TemplateID]
+	if !ok {
+		return nil
+	}
+	return pipelineTemplateNode(t)
+}
+
+// applyPipelineTemplate sets the template of the pipeline if the input has a pipelineTemplateId, null removes it
+func (s *Server) applyPipelineTemplate(p *pipeline, input map[string]interface{}) error {
+	value, ok := input["pipelineTemplateId"]
+	if !ok {
+		return nil
+	}
+
+	id, _ := value.(string)
+	if id != "" {
+		if _, exists := s.pipelineTemplates[id]; !exists {
+			return fmt.Errorf("No pipeline template found with id %s", id)
+		}
+	}
+	// a detached pipeline keeps the steps of its template
+	if t, ok := s.pipelineTemplates[p.TemplateID]; ok && id == "" {
+		p.Configuration = t.Configuration
+	}
+	p.TemplateID = id
+	return nil
+}
+
+func (s *Server) resolvePipelineTemplateCreate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	if input["organizationId"] != s.orgID {
+		return nil, fmt.Errorf("No organization found with id %v", input["organizationId"])
+	}
+
+	uuid := newUUID()
+	t := &pipelineTemplate{
+		ID:   graphQLID("PipelineTemplate", uuid),
+		UUID: uuid,
+	}
+	if err := applyPipelineTemplateInput(t, input); err != nil {
+		return nil, err
+	}
+	s.pipelineTemplates[t.ID] = t
+
+	return map[string]interface{}{
+		"pipelineTemplate": pipelineTemplateNode(t),
+	}, nil
+}
+
+func (s *Server) resolvePipelineTemplateUpdate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	id, _ := input["id"].(string)
+	t, ok := s.pipelineTemplates[id]
+	if !ok {
+		return nil, fmt.Errorf("No pipeline template found with id %s", id)
+	}
+
+	if err := applyPipelineTemplateInput(t, input); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"pipelineTemplate": pipelineTemplateNode(t),
+	}, nil
+}
+
+func applyPipelineTemplateInput(t *pipelineTemplate, input map[string]interface{}) error {
+	stringInputs := map[string]*string{
+		"name":          &t.Name,
+		"description":   &t.Description,
+		"configuration": &t.Configuration,
+	}
+	for key, field := range stringInputs {
+		if value, ok := input[key].(string); ok {
+			*field = value
+		}
+	}
+	if available, ok := input["available"].(bool); ok {
+		t.Available = available
+	}
+
+	if t.Name == "" {
+		return &validationError{field: "name", code: "blank", message: "can't be blank"}
+	}
+	if t.Configuration == "" {
+		return &validationError{field: "configuration", code: "blank", message: "can't be blank"}
+	}
+	return nil
+}
+
+func (s *Server) resolvePipelineTemplateDelete(args map[string]interface{}) (interface{}, error) {
+	id, _ := inputOf(args)["id"].(string)
+	if _, ok := s.pipelineTemplates[id]; !ok {
+		return nil, fmt.Errorf("No pipeline template found with id %s", id)
+	}
+	for _, p := range s.pipelines {
+		if p.TemplateID == id {
+			return nil, fmt.Errorf("Pipeline template is used by pipeline %s", p.Slug)
+		}
+	}
+
+	delete(s.pipelineTemplates, id)
+
+	return map[string]interface{}{
+		"deletedPipelineTemplateID": id,
+	}, nil
+}
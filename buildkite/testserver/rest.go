@@ -0,0 +1,410 @@
+package testserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type pipeline struct {
+	ID                  string
+	UUID                string
+	Slug                string
+	Name                string
+	Description         string
+	Repository          string
+	DefaultBranch       string
+	BranchConfiguration string
+	ClusterID           string
+	Environment         map[string]interface{}
+	Steps               []interface{}
+	Configuration       string
+	TemplateID          string
+	ProviderSettings    map[string]interface{}
+	CreatedAt           string
+}
+
+func (s *Server) serveREST(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, restPrefix), "/"), "/")
+
+	if len(path) == 2 && path[0] == "v2" && path[1] == "access-token" && r.Method == http.MethodGet {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{"uuid": s.tokenUUID, "scopes": s.Scopes})
+		return
+	}
+	if len(path) < 4 || path[0] != "v2" || path[1] != "organizations" {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "No route matches " + r.URL.Path})
+		return
+	}
+	if path[2] != s.OrgSlug {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "No organization found"})
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if scope := restScope(path[3], r.Method); !s.hasScope(scope) {
+		writeJSON(w, http.StatusForbidden, map[string]interface{}{
+			"message": "The API Access Token is missing the " + scope + " scope",
+		})
+		return
+	}
+
+	switch path[3] {
+	case "pipelines":
+		s.servePipelines(w, r, path[4:])
+	case "clusters":
+		s.serveClusters(w, r, path[4:])
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "No route matches " + r.URL.Path})
+	}
+}
+
+// restScope returns the scope a token needs for requests to the given collection
+func restScope(collection string, method string) string {
+	access := "write_"
+	if method == http.MethodGet {
+		access = "read_"
+	}
+	return access + collection
+}
+
+// hasScope reports whether the scope was granted to APIToken
+func (s *Server) hasScope(scope string) bool {
+	for _, granted := range s.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// servePipelines serves /v2/organizations/{org}/pipelines, path is the remainder of the URL path
+func (s *Server) servePipelines(w http.ResponseWriter, r *http.Request, path []string) {
+	switch {
+	case len(path) == 0 && r.Method == http.MethodPost:
+		s.createPipeline(w, r)
+	case len(path) == 1 && r.Method == http.MethodGet:
+		s.getPipeline(w, path[0])
+	case len(path) == 1 && r.Method == http.MethodPatch:
+		s.updatePipeline(w, r, path[0])
+	case len(path) == 1 && r.Method == http.MethodDelete:
+		s.deletePipeline(w, path[0])
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "No route matches " + r.URL.Path})
+	}
+}
+
+func (s *Server) createPipeline(w http.ResponseWriter, r *http.Request) {
+	body := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error()})
+		return
+	}
+
+	name, _ := body["name"].(string)
+	repository, _ := body["repository"].(string)
+	if name == "" || repository == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"message": "Validation Failed",
+			"errors": []map[string]interface{}{
+				{"field": "name", "code": "missing"},
+			},
+		})
+		return
+	}
+
+	if err := s.validatePipelineCluster(body); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"message": "Validation Failed",
+			"errors":  []map[string]interface{}{err.fields()},
+		})
+		return
+	}
+
+	p, err := s.newPipeline(name, repository)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"message": "Validation Failed",
+			"errors":  []map[string]interface{}{err.fields()},
+		})
+		return
+	}
+	applyPipelineAttributes(p, body)
+	s.addPipelineTeams(p, body["team_ids"], "MANAGE_BUILD_AND_READ")
+
+	writeJSON(w, http.StatusCreated, s.pipelineJSON(p))
+}
+
+func (s *Server) getPipeline(w http.ResponseWriter, slug string) {
+	p, ok := s.pipelines[slug]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.pipelineJSON(p))
+}
+
+func (s *Server) updatePipeline(w http.ResponseWriter, r *http.Request, slug string) {
+	p, ok := s.pipelines[slug]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+		return
+	}
+
+	body := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error()})
+		return
+	}
+	if err := s.validatePipelineCluster(body); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"message": "Validation Failed",
+			"errors":  []map[string]interface{}{err.fields()},
+		})
+		return
+	}
+	applyPipelineAttributes(p, body)
+
+	writeJSON(w, http.StatusOK, s.pipelineJSON(p))
+}
+
+func (s *Server) deletePipeline(w http.ResponseWriter, slug string) {
+	p, ok := s.pipelines[slug]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+		return
+	}
+
+	delete(s.pipelines, slug)
+	for id, tp := range s.teamPipelines {
+		if tp.PipelineID == p.ID {
+			delete(s.teamPipelines, id)
+		}
+	}
+	for id, schedule := range s.schedules {
+		if schedule.PipelineID == p.ID {
+			delete(s.schedules, id)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) newPipeline(name string, repository string) (*pipeline, *validationError) {
+	slug := slugify(name)
+	if _, exists := s.pipelines[slug]; exists {
+		return nil, &validationError{field: "name", code: "already_exists", message: "has already been taken"}
+	}
+
+	uuid := newUUID()
+	p := &pipeline{
+		ID:               graphQLID("Pipeline", uuid),
+		UUID:             uuid,
+		Slug:             slug,
+		Name:             name,
+		Repository:       repository,
+		DefaultBranch:    "master",
+		Environment:      map[string]interface{}{},
+		ProviderSettings: defaultProviderSettings(repositoryProvider(repository)),
+		CreatedAt:        now(),
+	}
+	s.pipelines[slug] = p
+	return p, nil
+}
+
+// addPipelineTeams gives the teams, referenced by their GraphQL id or UUID, access to the pipeline
+func (s *Server) addPipelineTeams(p *pipeline, teamIDs interface{}, accessLevel string) {
+	ids, _ := teamIDs.([]interface{})
+	for _, id := range ids {
+		for _, t := range s.teams {
+			if t.ID == id || t.UUID == id {
+				s.newTeamPipeline(t, p, accessLevel)
+			}
+		}
+	}
+}
+
+// validatePipelineCluster rejects a cluster_id which doesn't belong to a cluster of the organization
+func (s *Server) validatePipelineCluster(body map[string]interface{}) *validationError {
+	clusterID, _ := body["cluster_id"].(string)
+	if clusterID == "" {
+		return nil
+	}
+	if _, ok := s.clusters[clusterID]; !ok {
+		return &validationError{field: "cluster_id", code: "invalid", message: "is not a cluster of this organization"}
+	}
+	return nil
+}
+
+func applyPipelineAttributes(p *pipeline, body map[string]interface{}) {
+	previousProvider := p.provider()
+
+	stringAttributes := map[string]*string{
+		"name":                 &p.Name,
+		"description":          &p.Description,
+		"repository":           &p.Repository,
+		"default_branch":       &p.DefaultBranch,
+		"branch_configuration": &p.BranchConfiguration,
+		"configuration":        &p.Configuration,
+		"cluster_id":           &p.ClusterID,
+	}
+	for key, attribute := range stringAttributes {
+		if value, ok := body[key].(string); ok {
+			*attribute = value
+		}
+	}
+
+	if env, ok := body["env"].(map[string]interface{}); ok {
+		p.Environment = env
+	}
+	if steps, ok := body["steps"].([]interface{}); ok {
+		p.Steps = steps
+		// legacy steps replace the YAML steps of the pipeline
+		if len(steps) > 0 {
+			p.Configuration = ""
+		}
+	}
+	if p.provider() != previousProvider {
+		p.ProviderSettings = defaultProviderSettings(p.provider())
+	}
+	if settings, ok := body["provider_settings"].(map[string]interface{}); ok {
+		for key, value := range settings {
+			p.ProviderSettings[key] = value
+		}
+	}
+}
+
+func (p *pipeline) provider() string {
+	return repositoryProvider(p.Repository)
+}
+
+func (s *Server) pipelineJSON(p *pipeline) map[string]interface{} {
+	url := s.RestAPIURL() + "v2/organizations/" + s.OrgSlug + "/pipelines/" + p.Slug
+	webURL := "https://buildkite.com/" + s.OrgSlug + "/" + p.Slug
+
+	steps := p.Steps
+	if steps == nil {
+		steps = []interface{}{}
+	}
+
+	var clusterID interface{}
+	if p.ClusterID != "" {
+		clusterID = p.ClusterID
+	}
+
+	return map[string]interface{}{
+		"id":                   p.UUID,
+		"graphql_id":           p.ID,
+		"url":                  url,
+		"web_url":              webURL,
+		"builds_url":           url + "/builds",
+		"badge_url":            "https://badge.buildkite.com/" + p.UUID + ".svg",
+		"name":                 p.Name,
+		"slug":                 p.Slug,
+		"description":          p.Description,
+		"repository":           p.Repository,
+		"default_branch":       p.DefaultBranch,
+		"branch_configuration": p.BranchConfiguration,
+		"env":                  p.Environment,
+		"steps":                steps,
+		"configuration":        s.pipelineConfiguration(p),
+		"cluster_id":           clusterID,
+		"created_at":           p.CreatedAt,
+		"provider": map[string]interface{}{
+			"id":          p.provider(),
+			"settings":    p.ProviderSettings,
+			"webhook_url": "https://webhook.buildkite.com/deliver/" + p.UUID,
+		},
+	}
+}
+
+func repositoryProvider(repository string) string {
+	switch {
+	case strings.Contains(repository, "github.com"):
+		return "github"
+	case strings.Contains(repository, "bitbucket.org"):
+		return "bitbucket"
+	case strings.Contains(repository, "gitlab.com"):
+		return "gitlab"
+	// Buildkite knows the hosts of GitHub Enterprise and self-managed GitLab from the settings of the organization,
+	// the fake recognises them by name
+	case strings.Contains(repository, "github."):
+		return "github_enterprise"
+	case strings.Contains(repository, "gitlab."):
+		return "gitlab_ee"
+	case strings.Contains(repository, "beanstalkapp.com"):
+		return "beanstalk"
+	}
+	return "git"
+}
+
+// defaultProviderSettings are the settings Buildkite applies to a newly created pipeline
+func defaultProviderSettings(provider string) map[string]interface{} {
+	switch provider {
+	case "github", "github_enterprise":
+		return map[string]interface{}{
+			"trigger_mode":                                  "code",
+			"build_pull_requests":                           true,
+			"pull_request_branch_filter_enabled":            false,
+			"pull_request_branch_filter_configuration":      "",
+			"skip_pull_request_builds_for_existing_commits": true,
+			"build_pull_request_forks":                      false,
+			"prefix_pull_request_fork_branch_names":         true,
+			"build_tags":                                    false,
+			"publish_commit_status":                         true,
+			"publish_commit_status_per_step":                false,
+			"publish_blocked_as_pending":                    false,
+			"separate_pull_request_statuses":                false,
+			"filter_enabled":                                false,
+		}
+	case "bitbucket":
+		return map[string]interface{}{
+			"trigger_mode":                                  "code",
+			"build_pull_requests":                           true,
+			"pull_request_branch_filter_enabled":            false,
+			"pull_request_branch_filter_configuration":      "",
+			"skip_pull_request_builds_for_existing_commits": true,
+			"prefix_pull_request_fork_branch_names":         true,
+			"build_tags":                                    false,
+			"publish_commit_status":                         true,
+			"publish_commit_status_per_step":                false,
+		}
+	case "gitlab", "gitlab_ee":
+		return map[string]interface{}{
+			"trigger_mode":                                  "code",
+			"build_pull_requests":                           true,
+			"pull_request_branch_filter_enabled":            false,
+			"pull_request_branch_filter_configuration":      "",
+			"skip_pull_request_builds_for_existing_commits": true,
+			"build_tags":                                    false,
+			"publish_commit_status":                         true,
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// validationError is an invalid attribute, reported the way the REST API does for a 422 response
+type validationError struct {
+	field   string
+	code    string
+	message string
+}
+
+func (err *validationError) Error() string {
+	return strings.Title(strings.Replace(err.field, "_", " ", -1)) + " " + err.message
+}
+
+func (err *validationError) fields() map[string]interface{} {
+	return map[string]interface{}{"field": err.field, "code": err.code, "message": err.message}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
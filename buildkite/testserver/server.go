@@ -0,0 +1,174 @@
+// Package testserver implements an in-memory stand-in for the Buildkite REST and GraphQL APIs, so that the
+// provider can be exercised without talking to a real Buildkite organization.
+//
+// It only implements the endpoints, queries and mutations the provider sends and keeps all state in memory.
+package testserver
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// APIToken is the only API token accepted by the server
+	APIToken = "fake-buildkite-api-token"
+
+	restPrefix  = "/rest/"
+	graphQLPath = "/graphql"
+)
+
+// Server is a fake Buildkite API for a single organization
+type Server struct {
+	*httptest.Server
+
+	OrgSlug string
+	// PageSize caps the number of nodes per page of a GraphQL connection. It is deliberately small so that
+	// pagination gets exercised.
+	PageSize int
+	// Scopes are the scopes granted to APIToken, all scopes the provider needs by default
+	Scopes []string
+
+	mutex              sync.Mutex
+	tokenUUID          string
+	orgID              string
+	pipelines          map[string]*pipeline
+	teams              map[string]*team
+	teamMembers        map[string]*teamMember
+	teamPipelines      map[string]*teamPipeline
+	schedules          map[string]*pipelineSchedule
+	pipelineTemplates  map[string]*pipelineTemplate
+	orgMembers         map[string]*orgMember
+	invitations        map[string]*organizationInvitation
+	agentTokens        map[string]*agentToken
+	clusters           map[string]*cluster
+	clusterQueues      map[string]*clusterQueue
+	clusterAgentTokens map[string]*clusterAgentToken
+	suites             map[string]*suite
+	teamSuites         map[string]*teamSuite
+
+	// pagination and search arguments of the GraphQL request being resolved
+	first  int
+	after  string
+	search string
+}
+
+// New starts a fake Buildkite API for the organization orgSlug. It must be closed once it is no longer needed.
+func New(orgSlug string) *Server {
+	s := &Server{
+		OrgSlug:            orgSlug,
+		PageSize:           2,
+		Scopes:             []string{"read_pipelines", "write_pipelines", "read_clusters", "write_clusters", "graphql"},
+		tokenUUID:          newUUID(),
+		orgID:              graphQLID("Organization", newUUID()),
+		pipelines:          map[string]*pipeline{},
+		teams:              map[string]*team{},
+		teamMembers:        map[string]*teamMember{},
+		teamPipelines:      map[string]*teamPipeline{},
+		schedules:          map[string]*pipelineSchedule{},
+		pipelineTemplates:  map[string]*pipelineTemplate{},
+		orgMembers:         map[string]*orgMember{},
+		invitations:        map[string]*organizationInvitation{},
+		agentTokens:        map[string]*agentToken{},
+		clusters:           map[string]*cluster{},
+		clusterQueues:      map[string]*clusterQueue{},
+		clusterAgentTokens: map[string]*clusterAgentToken{},
+		suites:             map[string]*suite{},
+		teamSuites:         map[string]*teamSuite{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(restPrefix, s.authorized(s.serveREST))
+	mux.HandleFunc(graphQLPath, s.authorized(s.serveGraphQL))
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// RestAPIURL is the base URL of the fake REST API
+func (s *Server) RestAPIURL() string {
+	return s.URL + restPrefix
+}
+
+// GraphQLAPIURL is the endpoint of the fake GraphQL API
+func (s *Server) GraphQLAPIURL() string {
+	return s.URL + graphQLPath
+}
+
+// AddOrganizationMember adds a user to the organization, since users can't be created through the API. It returns
+// the UUID of the membership and the GraphQL id of the user.
+func (s *Server) AddOrganizationMember(name string, email string, role string) (string, string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	member := s.addOrganizationMember(name, email, role)
+	return member.UUID, member.UserID
+}
+
+func (s *Server) addOrganizationMember(name string, email string, role string) *orgMember {
+	member := &orgMember{
+		UUID:      newUUID(),
+		Role:      role,
+		CreatedAt: now(),
+		UserID:    graphQLID("User", newUUID()),
+		UserName:  name,
+		UserEmail: email,
+	}
+	member.ID = graphQLID("OrganizationMember", member.UUID)
+	s.orgMembers[member.UUID] = member
+
+	return member
+}
+
+func (s *Server) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+APIToken {
+			writeJSON(w, http.StatusUnauthorized, map[string]interface{}{
+				"message": "Authentication required. Please supply a valid API Access Token",
+			})
+			return
+		}
+		w.Header().Set("X-Request-Id", newUUID())
+		handler(w, r)
+	}
+}
+
+func newUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// graphQLID encodes ids the same way Buildkite does, the type of the node followed by its UUID
+func graphQLID(typeName string, uuid string) string {
+	return base64.StdEncoding.EncodeToString([]byte(typeName + "---" + uuid))
+}
+
+// nodeType returns the type a GraphQL id belongs to
+func nodeType(id string) string {
+	decoded, err := base64.StdEncoding.DecodeString(id)
+	if err != nil {
+		return ""
+	}
+	return strings.SplitN(string(decoded), "---", 2)[0]
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slugify(name string) string {
+	return strings.Trim(nonSlugCharacters.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
@@ -0,0 +1,210 @@
+package testserver
+
+import (
+	"fmt"
+)
+
+type suite struct {
+	ID            string
+	UUID          string
+	Slug          string
+	Name          string
+	DefaultBranch string
+	APIToken      string
+}
+
+type teamSuite struct {
+	ID          string
+	UUID        string
+	AccessLevel string
+	CreatedAt   string
+	TeamID      string
+	SuiteID     string
+}
+
+func (s *Server) suiteNode(suite *suite) map[string]interface{} {
+	teams := []map[string]interface{}{}
+	for _, ts := range s.teamSuites {
+		if ts.SuiteID == suite.ID {
+			teams = append(teams, teamSuiteNode(ts))
+		}
+	}
+
+	return map[string]interface{}{
+		"id":            suite.ID,
+		"uuid":          suite.UUID,
+		"slug":          suite.Slug,
+		"name":          suite.Name,
+		"defaultBranch": suite.DefaultBranch,
+		"url":           "https://buildkite.com/organizations/" + s.OrgSlug + "/analytics/suites/" + suite.Slug,
+		"teams":         s.connection(teams),
+	}
+}
+
+func teamSuiteNode(ts *teamSuite) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          ts.ID,
+		"uuid":        ts.UUID,
+		"accessLevel": ts.AccessLevel,
+		"createdAt":   ts.CreatedAt,
+		"team":        map[string]interface{}{"id": ts.TeamID},
+		"suite":       map[string]interface{}{"id": ts.SuiteID},
+	}
+}
+
+func (s *Server) resolveSuiteCreate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	if input["organizationId"] != s.orgID {
+		return nil, fmt.Errorf("No organization found with id %v", input["organizationId"])
+	}
+
+	name, _ := input["name"].(string)
+	if name == "" {
+		return nil, &validationError{field: "name", code: "blank", message: "can't be blank"}
+	}
+	slug := slugify(name)
+	for _, existing := range s.suites {
+		if existing.Slug == slug {
+			return nil, &validationError{field: "name", code: "already_exists", message: "has already been taken"}
+		}
+	}
+
+	teamID, _ := input["teamId"].(string)
+	t := s.teamByID(teamID)
+	if t == nil {
+		return nil, fmt.Errorf("No team found with id %s", teamID)
+	}
+
+	uuid := newUUID()
+	created := &suite{
+		ID:       graphQLID("Suite", uuid),
+		UUID:     uuid,
+		Slug:     slug,
+		Name:     name,
+		APIToken: newUUID(),
+	}
+	created.DefaultBranch, _ = input["defaultBranch"].(string)
+	s.suites[created.ID] = created
+	s.newTeamSuite(t, created, "MANAGE_AND_READ")
+
+	return map[string]interface{}{
+		"suiteEdge": map[string]interface{}{
+			"node": s.suiteNode(created),
+		},
+		"apiToken": created.APIToken,
+	}, nil
+}
+
+func (s *Server) resolveSuiteUpdate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	id, _ := input["id"].(string)
+	updated, ok := s.suites[id]
+	if !ok {
+		return nil, fmt.Errorf("No suite found with id %s", id)
+	}
+
+	if name, ok := input["name"].(string); ok {
+		updated.Name = name
+	}
+	if defaultBranch, ok := input["defaultBranch"].(string); ok {
+		updated.DefaultBranch = defaultBranch
+	}
+
+	return map[string]interface{}{
+		"suite": s.suiteNode(updated),
+	}, nil
+}
+
+func (s *Server) resolveSuiteDelete(args map[string]interface{}) (interface{}, error) {
+	id, _ := inputOf(args)["id"].(string)
+	if _, ok := s.suites[id]; !ok {
+		return nil, fmt.Errorf("No suite found with id %s", id)
+	}
+
+	delete(s.suites, id)
+	for teamSuiteID, ts := range s.teamSuites {
+		if ts.SuiteID == id {
+			delete(s.teamSuites, teamSuiteID)
+		}
+	}
+
+	return map[string]interface{}{
+		"deletedSuiteID": id,
+	}, nil
+}
+
+func (s *Server) newTeamSuite(t *team, suite *suite, accessLevel string) *teamSuite {
+	uuid := newUUID()
+	ts := &teamSuite{
+		ID:          graphQLID("TeamSuite", uuid),
+		UUID:        uuid,
+		AccessLevel: accessLevel,
+		CreatedAt:   now(),
+		TeamID:      t.ID,
+		SuiteID:     suite.ID,
+	}
+	s.teamSuites[ts.ID] = ts
+	return ts
+}
+
+func (s *Server) resolveTeamSuiteCreate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	teamID, _ := input["teamID"].(string)
+	suiteID, _ := input["suiteID"].(string)
+
+	t := s.teamByID(teamID)
+	if t == nil {
+		return nil, fmt.Errorf("No team found with id %s", teamID)
+	}
+	suite, ok := s.suites[suiteID]
+	if !ok {
+		return nil, fmt.Errorf("No suite found with id %s", suiteID)
+	}
+	for _, ts := range s.teamSuites {
+		if ts.TeamID == teamID && ts.SuiteID == suiteID {
+			return nil, fmt.Errorf("Suite has already been added to this team")
+		}
+	}
+
+	accessLevel, ok := input["accessLevel"].(string)
+	if !ok || accessLevel == "" {
+		accessLevel = "READ_ONLY"
+	}
+	ts := s.newTeamSuite(t, suite, accessLevel)
+
+	return map[string]interface{}{
+		"teamSuiteEdge": map[string]interface{}{
+			"node": teamSuiteNode(ts),
+		},
+	}, nil
+}
+
+func (s *Server) resolveTeamSuiteUpdate(args map[string]interface{}) (interface{}, error) {
+	input := inputOf(args)
+	id, _ := input["id"].(string)
+	ts, ok := s.teamSuites[id]
+	if !ok {
+		return nil, fmt.Errorf("No team suite found with id %s", id)
+	}
+
+	if accessLevel, ok := input["accessLevel"].(string); ok {
+		ts.AccessLevel = accessLevel
+	}
+
+	return map[string]interface{}{
+		"teamSuite": teamSuiteNode(ts),
+	}, nil
+}
+
+func (s *Server) resolveTeamSuiteDelete(args map[string]interface{}) (interface{}, error) {
+	id, _ := inputOf(args)["id"].(string)
+	if _, ok := s.teamSuites[id]; !ok {
+		return nil, fmt.Errorf("No team suite found with id %s", id)
+	}
+
+	delete(s.teamSuites, id)
+
+	return map[string]interface{}{
+		"deletedTeamSuiteID": id,
+	}, nil
+}
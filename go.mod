@@ -1,10 +1,12 @@
 module github.com/saymedia/terraform-buildkite
 
-go 1.13
+go 1.16
 
 require (
 	github.com/hashicorp/terraform-plugin-sdk v1.7.0
 	github.com/machinebox/graphql v0.2.2
 	github.com/matryer/is v1.2.0 // indirect
 	github.com/pkg/errors v0.9.1
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
+	gopkg.in/yaml.v3 v3.0.1
 )
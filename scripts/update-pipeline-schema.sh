@@ -0,0 +1,22 @@
+#!/bin/bash
+
+set -euo pipefail
+
+SCHEMA_URL="https://raw.githubusercontent.com/buildkite/pipeline-schema/main/schema.json"
+FILE="$(dirname "$0")/../buildkite/provider/pipeline_schema.json"
+
+main() {
+    local schema
+
+    schema="$(mktemp)"
+    trap 'rm -f "${schema}"' EXIT
+
+    curl -sSfL "${SCHEMA_URL}" -o "${schema}"
+    # refuse to replace the schema with anything that isn't JSON
+    jq -e '.definitions' "${schema}" > /dev/null
+
+    cp "${schema}" "${FILE}"
+    echo "Updated ${FILE}, run the tests before committing it"
+}
+
+main "$@"